@@ -107,13 +107,46 @@ echo -e "${BLUE}Setting up configuration...${NC}"
 mkdir -p "$HOME/.config/gopick"
 echo -e "${GREEN}✓${NC} Configuration directory created"
 
+echo
+echo -e "${BLUE}Setting up shell integration...${NC}"
+SHELL_NAME=$(basename "$SHELL")
+case "$SHELL_NAME" in
+    bash)
+        INIT_RC="$HOME/.bashrc"
+        INIT_LINE='eval "$(gopick shell-init bash)"'
+        ;;
+    zsh)
+        INIT_RC="$HOME/.zshrc"
+        INIT_LINE='eval "$(gopick shell-init zsh)"'
+        ;;
+    fish)
+        INIT_RC="$HOME/.config/fish/config.fish"
+        INIT_LINE='gopick shell-init fish | source'
+        ;;
+    *)
+        INIT_RC=""
+        ;;
+esac
+
+if [ -z "$INIT_RC" ]; then
+    echo -e "${YELLOW}No shell integration for $SHELL_NAME; commands will be typed into the terminal instead${NC}"
+elif grep -q "gopick shell-init" "$INIT_RC" 2>/dev/null; then
+    echo -e "${GREEN}✓${NC} Shell integration already configured in $INIT_RC"
+else
+    mkdir -p "$(dirname "$INIT_RC")"
+    echo "" >> "$INIT_RC"
+    echo "# Added by gopick installer: Ctrl-G opens gopick" >> "$INIT_RC"
+    echo "$INIT_LINE" >> "$INIT_RC"
+    echo -e "${GREEN}✓${NC} Added shell integration to $INIT_RC"
+fi
+
 echo
 echo -e "${BLUE}╔════════════════════════════════════════╗${NC}"
 echo -e "${BLUE}║     Installation Complete! 🎉          ║${NC}"
 echo -e "${BLUE}╚════════════════════════════════════════╝${NC}"
 echo
 echo "Quick start:"
-echo "  1. Type 'gopick' to launch"
+echo "  1. Type 'gopick' (or press Ctrl-G) to launch"
 echo "  2. Search for any Go package"
 echo "  3. Press [h] for help"
 echo
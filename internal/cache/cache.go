@@ -21,7 +21,43 @@ type Package struct {
 	ImportPath  string `json:"import_path"`
 	Description string `json:"description"`
 	Version     string `json:"version,omitempty"`
-	IsInstalled bool   `json:"is_installed,omitempty"`
+	ModulePath  string `json:"module_path,omitempty"`
+	// set when the user chose Version explicitly
+	Pinned      bool `json:"pinned,omitempty"`
+	IsInstalled bool `json:"is_installed,omitempty"`
+
+	// install status relative to the current go.mod / go.work
+	InModule        bool   `json:"in_module,omitempty"`
+	RequiredVersion string `json:"required_version,omitempty"`
+	Indirect        bool   `json:"indirect,omitempty"`
+	InModCache      bool   `json:"in_mod_cache,omitempty"`
+
+	// package main commands are installed with go install rather than go get
+	Command bool `json:"command,omitempty"`
+	// the command's binary is present in GOBIN
+	InGoBin bool `json:"in_gobin,omitempty"`
+	// listed by a tool directive of the current go.mod
+	Tool bool `json:"tool,omitempty"`
+	// part of the standard library of the active Go version
+	Stdlib bool `json:"stdlib,omitempty"`
+	// banned by the package policy, with its explanation
+	Banned       bool   `json:"banned,omitempty"`
+	PolicyReason string `json:"policy_reason,omitempty"`
+
+	// filled in by FetchPackageDetails
+	License    string `json:"license,omitempty"`
+	Published  string `json:"published,omitempty"`
+	ImportedBy int    `json:"imported_by,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	Readme     string `json:"readme,omitempty"`
+
+	// supplied by providers that know it, e.g. a company catalog
+	Stars int `json:"stars,omitempty"`
+	// position used by the relevance order, see search.Rank
+	Rank float64 `json:"-"`
+	// search filters that could not be checked for lack of data, see
+	// search.Query.Filter
+	Unverified []string `json:"-"`
 }
 
 type Cache struct {
@@ -90,6 +126,24 @@ func (c *Cache) Set(query string, packages []Package) error {
 	return nil
 }
 
+// returns the cached details of a single package
+func (c *Cache) GetDetails(importPath string) (*Package, bool) {
+	entry, found := c.Get(detailsKey(importPath))
+	if !found || len(entry.Results) != 1 {
+		return nil, false
+	}
+	return &entry.Results[0], true
+}
+
+func (c *Cache) SetDetails(pkg Package) error {
+	return c.Set(detailsKey(pkg.ImportPath), []Package{pkg})
+}
+
+// details share the query cache; the prefix keeps them apart from searches
+func detailsKey(importPath string) string {
+	return "details:" + importPath
+}
+
 func (c *Cache) Clear() error {
 	entries, err := os.ReadDir(c.dir)
 	if err != nil {
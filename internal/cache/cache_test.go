@@ -201,6 +201,32 @@ func TestCacheAtomicWrite(t *testing.T) {
 	assert.NotNil(t, entry)
 }
 
+func TestCacheDetails(t *testing.T) {
+	tempDir := t.TempDir()
+	c, err := New(tempDir, 7)
+	require.NoError(t, err)
+
+	_, found := c.GetDetails("github.com/test/pkg")
+	assert.False(t, found)
+
+	pkg := Package{
+		Name:       "pkg",
+		ImportPath: "github.com/test/pkg",
+		License:    "MIT",
+		ImportedBy: 42,
+		Readme:     "# pkg",
+	}
+	require.NoError(t, c.SetDetails(pkg))
+
+	cached, found := c.GetDetails("github.com/test/pkg")
+	require.True(t, found)
+	assert.Equal(t, pkg, *cached)
+
+	// a search for the same text is a different entry
+	_, found = c.Get("github.com/test/pkg")
+	assert.False(t, found)
+}
+
 func TestPackageStruct(t *testing.T) {
 	pkg := Package{
 		Name:        "example",
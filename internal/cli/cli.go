@@ -0,0 +1,1057 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+	"github.com/MdSadiqMd/gopick/internal/config"
+	"github.com/MdSadiqMd/gopick/internal/history"
+	"github.com/MdSadiqMd/gopick/internal/imports"
+	"github.com/MdSadiqMd/gopick/internal/license"
+	"github.com/MdSadiqMd/gopick/internal/packages"
+	"github.com/MdSadiqMd/gopick/internal/policy"
+	"github.com/MdSadiqMd/gopick/internal/proxy"
+	"github.com/MdSadiqMd/gopick/internal/search"
+	"github.com/MdSadiqMd/gopick/internal/shell"
+	"github.com/MdSadiqMd/gopick/internal/vuln"
+)
+
+const usage = `Usage:
+  gopick                          Launch the interactive search
+  gopick search [--format F] [--sort S] <query>
+                                  Search for packages (F: plain, json, ndjson, tsv;
+                                  S: relevance, imported-by, stars, published, installed).
+                                  The query may filter with license:MIT, std:false,
+                                  host:github.com, installed:true, updated:<1y and
+                                  exclude with -word (put -- before a leading -word)
+  gopick get [--print|--dry-run] [--force] [--module M,...] [--file F] <pkg>...
+                                  Install packages (pkg or pkg@version), optionally
+                                  into the given go.work modules, then import them
+                                  into the Go file F (default $GOPICK_FILE)
+  gopick import [--file F] <[name=]pkg>...
+                                  Add imports to the Go file F (default $GOPICK_FILE);
+                                  name may be an alias, _ or .
+  gopick install [--print] [--force] <cmd>...
+                                  Install commands into GOBIN (cmd or cmd@version)
+  gopick tool [list [--json]|add <cmd>...|remove <cmd>...]
+                                  Manage the tool directives of go.mod
+  gopick deps [list|remove|upgrade|downgrade] [mod[@version]...]
+                                  List, remove, upgrade (default @latest) or
+                                  downgrade the requirements of go.mod
+  gopick outdated [--json] [--print [--major]]
+                                  Report newer versions of the requirements of go.mod,
+                                  or print the go get command that applies them
+  gopick vuln [--json] <pkg>...   Check the module versions an install would add
+                                  against the Go vulnerability database
+  gopick versions [--json] [-n N] <pkg>
+                                  List module versions from GOPROXY, newest first
+  gopick history [--json] [-n N]  Show recent history
+  gopick history clear            Clear history
+  gopick cache clear              Clear the search cache
+  gopick shell-init [--no-bind] <bash|zsh|fish>
+                                  Print shell integration with a Ctrl-G binding
+  gopick help                     Show this help
+`
+
+var (
+	// ErrUsage is returned when the command line could not be understood
+	ErrUsage = errors.New("invalid usage")
+	// ErrVulnerable is returned by the vuln command when a check found something
+	ErrVulnerable = errors.New("vulnerable module versions found")
+	// ErrLicenseDenied is returned when the license policy blocks an install
+	ErrLicenseDenied = errors.New("blocked by the license policy")
+	// ErrBanned is returned when installing modules the package policy bans
+	ErrBanned = errors.New("banned by the package policy (use --force to install anyway)")
+)
+
+type App struct {
+	config     *config.Config
+	cache      *cache.Cache
+	history    *history.History
+	searcher   search.Searcher
+	pkgManager *packages.Manager
+	proxy      *proxy.Client
+	vulnDB     *vuln.Client
+	policy     *policy.Policy
+	// set once the policy was read, even if that failed
+	policyLoaded bool
+
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func New(cfg *config.Config, c *cache.Cache, h *history.History, pm *packages.Manager, s search.Searcher, pc *proxy.Client, stdout, stderr io.Writer) *App {
+	return &App{
+		config:     cfg,
+		cache:      c,
+		history:    h,
+		searcher:   s,
+		pkgManager: pm,
+		proxy:      pc,
+		stdout:     stdout,
+		stderr:     stderr,
+	}
+}
+
+// runs the subcommand named by args[0]
+func (a *App) Run(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(a.stderr, usage)
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "search":
+		return a.runSearch(args[1:])
+	case "get":
+		return a.runGet(args[1:])
+	case "install":
+		return a.runInstall(args[1:])
+	case "import":
+		return a.runImport(args[1:])
+	case "tool":
+		return a.runTool(args[1:])
+	case "deps":
+		return a.runDeps(args[1:])
+	case "outdated":
+		return a.runOutdated(args[1:])
+	case "vuln":
+		return a.runVuln(args[1:])
+	case "versions":
+		return a.runVersions(args[1:])
+	case "history":
+		return a.runHistory(args[1:])
+	case "cache":
+		return a.runCache(args[1:])
+	case "shell-init":
+		return a.runShellInit(args[1:])
+	case "help", "-h", "--help":
+		fmt.Fprint(a.stdout, usage)
+		return nil
+	default:
+		fmt.Fprintf(a.stderr, "unknown command %q\n\n", args[0])
+		fmt.Fprint(a.stderr, usage)
+		return ErrUsage
+	}
+}
+
+func (a *App) runSearch(args []string) error {
+	fs := a.newFlagSet("search")
+	format := fs.String("format", FormatPlain, "output format: plain, json, ndjson or tsv")
+	asJSON := fs.Bool("json", false, "shorthand for --format json")
+	sortName := fs.String("sort", "relevance", "order: relevance, imported-by, stars, published or installed")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	sortMode, err := search.ParseSortMode(*sortName)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "search: %v\n", err)
+		return ErrUsage
+	}
+
+	if *asJSON {
+		*format = FormatJSON
+	}
+	if !validFormat(*format) {
+		fmt.Fprintf(a.stderr, "search: unknown format %q\n", *format)
+		return ErrUsage
+	}
+
+	raw := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if raw == "" {
+		fmt.Fprintln(a.stderr, "search: missing query")
+		return ErrUsage
+	}
+	query, err := search.ParseQuery(raw)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "search: %v\n", err)
+		return ErrUsage
+	}
+	if query.Text == "" {
+		fmt.Fprintln(a.stderr, "search: filters need search terms to apply to")
+		return ErrUsage
+	}
+
+	pkgs, source, err := a.search(query.Text)
+	if err != nil {
+		return err
+	}
+	if source == sourceFallback && *format == FormatPlain {
+		fmt.Fprintln(a.stderr, "note: search providers unreachable, showing offline results")
+	}
+	pkgs = query.Filter(pkgs, time.Now())
+	a.getPolicy().Apply(pkgs)
+
+	entries, _ := a.history.GetAll()
+	search.Rank(pkgs, search.NewBooster(entries, a.config.TeamGoMods))
+	search.Sort(pkgs, sortMode)
+
+	return writeSearchResults(a.stdout, *format, pkgs, source)
+}
+
+// where search results came from
+const (
+	sourceCache     = "cache"
+	sourceProviders = "providers"
+	// the fallback providers, e.g. the module cache while offline
+	sourceFallback = "fallback"
+)
+
+// looks the query up in the cache first, then asks the search providers.
+// Answers of the fallback providers are not cached, so the next search
+// asks the providers again
+func (a *App) search(query string) ([]cache.Package, string, error) {
+	if cached, found := a.cache.Get(query); found {
+		return a.markInstalled(cached.Results), sourceCache, nil
+	}
+
+	pkgs, fallback, err := search.SearchSource(a.searcher, query)
+	if err != nil {
+		return nil, "", fmt.Errorf("search failed: %w", err)
+	}
+
+	pkgs = a.markInstalled(pkgs)
+	if fallback {
+		return pkgs, sourceFallback, nil
+	}
+	a.cache.Set(query, pkgs)
+
+	return pkgs, sourceProviders, nil
+}
+
+func (a *App) runGet(args []string) error {
+	fs := a.newFlagSet("get")
+	printOnly := fs.Bool("print", false, "print the go get command instead of running it")
+	dryRun := fs.Bool("dry-run", false, "show how go.mod and go.sum would change without installing")
+	moduleList := fs.String("module", "", "comma-separated workspace modules (path or directory) to add the packages to")
+	force := fs.Bool("force", false, "install or print modules banned by the package policy")
+	file := fs.String("file", os.Getenv(imports.FileEnv), "Go file to import the packages into once installed")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	if fs.NArg() == 0 {
+		fmt.Fprintln(a.stderr, "get: missing package")
+		return ErrUsage
+	}
+
+	var pkgs []cache.Package
+	var specs []imports.Spec
+	for _, arg := range fs.Args() {
+		spec, pkg := parseImportArg(arg)
+		pkgs = append(pkgs, pkg)
+		specs = append(specs, spec)
+	}
+	pkgs = a.markInstalled(pkgs)
+	a.noteStdlib(pkgs)
+
+	modules, err := a.targetModules(*moduleList)
+	if err != nil {
+		return err
+	}
+
+	if err := a.checkPolicy(pkgs, packages.NeedsInstall, !*dryRun && !*force); err != nil {
+		return err
+	}
+	a.warnVulnerable(pkgs, packages.NeedsInstall)
+	if err := a.checkLicenses(pkgs, packages.NeedsInstall); err != nil {
+		return err
+	}
+
+	if *printOnly {
+		cwd, _ := os.Getwd()
+		command := a.pkgManager.GetInstallCommandIn(pkgs, modules, cwd)
+		if command != "" {
+			fmt.Fprintln(a.stdout, command)
+		}
+		return nil
+	}
+
+	if *dryRun {
+		if len(modules) == 0 {
+			preview, err := a.pkgManager.PreviewInstall(pkgs)
+			if err != nil {
+				return err
+			}
+			writePreview(a.stdout, preview)
+			return nil
+		}
+
+		for i, mod := range modules {
+			preview, err := a.pkgManager.PreviewInstallIn(mod, pkgs)
+			if err != nil {
+				return fmt.Errorf("%s: %w", mod.Path, err)
+			}
+			if i > 0 {
+				fmt.Fprintln(a.stdout)
+			}
+			fmt.Fprintf(a.stdout, "# %s\n", mod.Path)
+			writePreview(a.stdout, preview)
+		}
+		return nil
+	}
+
+	progress := func(msg string, percent float64) {
+		fmt.Fprintf(a.stderr, "[%3.0f%%] %s\n", percent, msg)
+	}
+
+	// like the TUI, a failure doesn't stop the remaining packages or modules
+	var failures packages.InstallError
+	var errs []error
+	collect := func(err error, mod string) {
+		if err == nil {
+			return
+		}
+		var installErr packages.InstallError
+		if errors.As(err, &installErr) {
+			failures = append(failures, installErr...)
+		}
+		if mod != "" {
+			err = fmt.Errorf("%s: %w", mod, err)
+		}
+		errs = append(errs, err)
+	}
+
+	if len(modules) == 0 {
+		collect(a.pkgManager.InstallPackages(context.Background(), pkgs, progress), "")
+	}
+	for _, mod := range modules {
+		fmt.Fprintf(a.stderr, "==> %s\n", mod.Path)
+		collect(a.pkgManager.InstallPackagesIn(context.Background(), mod, pkgs, progress), mod.Path)
+	}
+
+	for _, pkg := range pkgs {
+		if packages.NeedsInstall(pkg) && !failures.Failed(pkg.ImportPath) {
+			a.history.Add(pkg.Name, pkg.ImportPath, history.ActionInstalled)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if *file != "" {
+		return a.addImports(*file, specs)
+	}
+	return nil
+}
+
+// adds imports to a Go file without installing anything, for packages that
+// are already required or part of the standard library
+func (a *App) runImport(args []string) error {
+	fs := a.newFlagSet("import")
+	file := fs.String("file", os.Getenv(imports.FileEnv), "Go file to add the imports to")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	if *file == "" {
+		fmt.Fprintf(a.stderr, "import: missing --file (or $%s)\n", imports.FileEnv)
+		return ErrUsage
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(a.stderr, "import: missing package")
+		return ErrUsage
+	}
+
+	var specs []imports.Spec
+	for _, arg := range fs.Args() {
+		spec, _ := parseImportArg(arg)
+		specs = append(specs, spec)
+	}
+	return a.addImports(*file, specs)
+}
+
+func (a *App) addImports(file string, specs []imports.Spec) error {
+	added, err := imports.AddToFile(file, specs, a.pkgManager.IsStdlib)
+	if err != nil {
+		return fmt.Errorf("failed to add imports to %s: %w", file, err)
+	}
+
+	if len(added) == 0 {
+		fmt.Fprintf(a.stderr, "%s already imports the packages\n", file)
+	}
+	for _, spec := range added {
+		fmt.Fprintf(a.stdout, "%s: import %s\n", file, spec)
+	}
+	return nil
+}
+
+// installs package main commands with go install; binaries already in
+// GOBIN are skipped unless a version is given
+func (a *App) runInstall(args []string) error {
+	fs := a.newFlagSet("install")
+	printOnly := fs.Bool("print", false, "print the go install command instead of running it")
+	force := fs.Bool("force", false, "install or print commands banned by the package policy")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	if fs.NArg() == 0 {
+		fmt.Fprintln(a.stderr, "install: missing command")
+		return ErrUsage
+	}
+
+	var pkgs []cache.Package
+	for _, arg := range fs.Args() {
+		pkg := parsePackageArg(arg)
+		// go install itself reports packages that are not commands
+		pkg.Command = true
+		pkgs = append(pkgs, pkg)
+	}
+	pkgs = a.markInstalled(pkgs)
+
+	if err := a.checkPolicy(pkgs, packages.NeedsToolInstall, !*force); err != nil {
+		return err
+	}
+	a.warnVulnerable(pkgs, packages.NeedsToolInstall)
+	if err := a.checkLicenses(pkgs, packages.NeedsToolInstall); err != nil {
+		return err
+	}
+
+	if *printOnly {
+		if command := a.pkgManager.GetToolInstallCommand(pkgs); command != "" {
+			fmt.Fprintln(a.stdout, command)
+		}
+		return nil
+	}
+
+	for _, pkg := range pkgs {
+		if !packages.NeedsToolInstall(pkg) {
+			fmt.Fprintf(a.stderr, "✓ %s already installed in %s\n", packages.BinaryName(pkg.ImportPath), a.pkgManager.GoBin())
+			continue
+		}
+
+		err := a.pkgManager.InstallTool(context.Background(), packages.ToolTarget(pkg), func(line string) {
+			fmt.Fprintln(a.stderr, line)
+		})
+		if err != nil {
+			return err
+		}
+		a.history.Add(pkg.Name, pkg.ImportPath, history.ActionInstalled)
+	}
+
+	return nil
+}
+
+// lists, adds or removes tool directives of the current module
+func (a *App) runTool(args []string) error {
+	project := a.pkgManager.Project()
+	if project == nil {
+		return packages.ErrNoProject
+	}
+	mod := project.Modules[0]
+
+	action := "list"
+	if len(args) > 0 {
+		action, args = args[0], args[1:]
+	}
+
+	switch action {
+	case "list":
+		fs := a.newFlagSet("tool list")
+		asJSON := fs.Bool("json", false, "print tools as JSON")
+		if err := fs.Parse(args); err != nil {
+			return ErrUsage
+		}
+
+		if *asJSON {
+			tools := mod.Tools
+			if tools == nil {
+				tools = []string{}
+			}
+			return a.writeJSON(tools)
+		}
+		for _, tool := range mod.Tools {
+			line := tool
+			if req, ok := mod.Lookup(tool); ok && req.Version != "" {
+				line += "  " + req.Version
+			}
+			fmt.Fprintln(a.stdout, line)
+		}
+		return nil
+
+	case "add":
+		if len(args) == 0 {
+			fmt.Fprintln(a.stderr, "tool add: missing command")
+			return ErrUsage
+		}
+		for _, arg := range args {
+			pkg := parsePackageArg(arg)
+			err := a.pkgManager.AddTool(context.Background(), mod, packages.ToolDirectiveTarget(pkg), func(line string) {
+				fmt.Fprintln(a.stderr, line)
+			})
+			if err != nil {
+				return err
+			}
+			a.history.Add(pkg.Name, pkg.ImportPath, history.ActionInstalled)
+		}
+		return nil
+
+	case "remove":
+		if len(args) == 0 {
+			fmt.Fprintln(a.stderr, "tool remove: missing command")
+			return ErrUsage
+		}
+		for _, arg := range args {
+			if err := a.pkgManager.RemoveTool(mod, arg); err != nil {
+				return err
+			}
+			mod = a.pkgManager.Project().Modules[0]
+			fmt.Fprintf(a.stdout, "Removed tool %s\n", arg)
+		}
+		return nil
+
+	default:
+		fmt.Fprintf(a.stderr, "tool: unknown action %q\n", action)
+		return ErrUsage
+	}
+}
+
+// lists the requirements of the current module, or removes, upgrades or
+// downgrades them with go get
+func (a *App) runDeps(args []string) error {
+	project := a.pkgManager.Project()
+	if project == nil {
+		return packages.ErrNoProject
+	}
+	mod := project.Modules[0]
+
+	action := "list"
+	if len(args) > 0 {
+		action, args = args[0], args[1:]
+	}
+
+	if action == "list" {
+		fs := a.newFlagSet("deps list")
+		asJSON := fs.Bool("json", false, "print requirements as JSON")
+		if err := fs.Parse(args); err != nil {
+			return ErrUsage
+		}
+
+		reqs := mod.Requirements()
+		if *asJSON {
+			if reqs == nil {
+				reqs = []packages.Requirement{}
+			}
+			return a.writeJSON(reqs)
+		}
+		for _, req := range reqs {
+			line := req.Path + "  " + req.Version
+			if req.Indirect {
+				line += "  // indirect"
+			}
+			fmt.Fprintln(a.stdout, line)
+		}
+		return nil
+	}
+
+	if action != "remove" && action != "upgrade" && action != "downgrade" {
+		fmt.Fprintf(a.stderr, "deps: unknown action %q\n", action)
+		return ErrUsage
+	}
+	if len(args) == 0 {
+		fmt.Fprintf(a.stderr, "deps %s: missing module\n", action)
+		return ErrUsage
+	}
+
+	progress := func(line string) {
+		fmt.Fprintln(a.stderr, line)
+	}
+
+	for _, arg := range args {
+		modulePath, version, _ := strings.Cut(arg, "@")
+		current, ok := mod.Lookup(modulePath)
+		if !ok || current.Path != modulePath {
+			return fmt.Errorf("%s is not required by %s", modulePath, mod.Path)
+		}
+
+		var err error
+		historyAction := history.ActionRemoved
+		switch action {
+		case "remove":
+			err = a.pkgManager.RemoveDependency(context.Background(), mod, modulePath, progress)
+		case "upgrade":
+			if version == "" {
+				version = "latest"
+			}
+			historyAction = history.ActionUpgraded
+			err = a.pkgManager.UpdateDependency(context.Background(), mod, modulePath, version, progress)
+		case "downgrade":
+			if version == "" {
+				fmt.Fprintln(a.stderr, "deps downgrade: expected module@version")
+				return ErrUsage
+			}
+			historyAction = history.ActionDowngraded
+			err = a.pkgManager.UpdateDependency(context.Background(), mod, modulePath, version, progress)
+		}
+		if err != nil {
+			return err
+		}
+
+		a.history.Add(path.Base(modulePath), modulePath, historyAction)
+		mod = a.pkgManager.Project().Modules[0]
+	}
+
+	return nil
+}
+
+// compares the requirements of the current module with the latest versions
+// from GOPROXY
+func (a *App) runOutdated(args []string) error {
+	fs := a.newFlagSet("outdated")
+	asJSON := fs.Bool("json", false, "print updates as JSON")
+	printOnly := fs.Bool("print", false, "print the go get command that applies the patch and minor updates")
+	major := fs.Bool("major", false, "with --print, include major updates")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	project := a.pkgManager.Project()
+	if project == nil {
+		return packages.ErrNoProject
+	}
+
+	updates, err := a.pkgManager.Outdated(project.Modules[0])
+	if err != nil {
+		return fmt.Errorf("failed to check for updates: %w", err)
+	}
+
+	switch {
+	case *printOnly:
+		var pkgs []cache.Package
+		for _, u := range updates {
+			if u.Kind != packages.UpdateMajor || *major {
+				pkgs = append(pkgs, u.Package())
+			}
+		}
+		if command := a.pkgManager.GetInstallCommand(pkgs); command != "" {
+			fmt.Fprintln(a.stdout, command)
+		}
+	case *asJSON:
+		if updates == nil {
+			updates = []packages.Update{}
+		}
+		return a.writeJSON(updates)
+	default:
+		writeOutdated(a.stdout, updates)
+	}
+
+	return nil
+}
+
+// checks the module versions that installing the packages would add against
+// the vulnerability database and fails when any is affected
+func (a *App) runVuln(args []string) error {
+	fs := a.newFlagSet("vuln")
+	asJSON := fs.Bool("json", false, "print the findings as JSON")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	if fs.NArg() == 0 {
+		fmt.Fprintln(a.stderr, "vuln: missing package")
+		return ErrUsage
+	}
+
+	var pkgs []cache.Package
+	for _, arg := range fs.Args() {
+		pkgs = append(pkgs, parsePackageArg(arg))
+	}
+	pkgs = a.markInstalled(pkgs)
+
+	reports, err := a.checkVulns(pkgs)
+	if err != nil {
+		return fmt.Errorf("vulnerability check failed: %w", err)
+	}
+
+	if *asJSON {
+		if err := a.writeJSON(reports); err != nil {
+			return err
+		}
+	} else {
+		writeVulnReports(a.stdout, reports)
+	}
+
+	for _, r := range reports {
+		if len(r.Findings) > 0 {
+			return ErrVulnerable
+		}
+	}
+	return nil
+}
+
+// vulnReport lists the vulnerabilities of one module version
+type vulnReport struct {
+	Path     string         `json:"path"`
+	Version  string         `json:"version"`
+	Findings []vuln.Finding `json:"findings"`
+}
+
+// checks the module version each package would be installed at. Versions
+// that only go get would pick are looked up as @latest on GOPROXY, and the
+// standard library is checked at the version of the go command
+func (a *App) checkVulns(pkgs []cache.Package) ([]vulnReport, error) {
+	db := a.getVulnDB()
+
+	seen := make(map[module.Version]bool)
+	var reports []vulnReport
+	for _, pkg := range pkgs {
+		mv := a.pkgManager.InstallModule(pkg)
+		if mv.Version == "" && pkg.Stdlib {
+			return nil, fmt.Errorf("failed to tell the Go version %s comes with", pkg.ImportPath)
+		}
+		if mv.Version == "" {
+			info, err := a.getProxy().Latest(mv.Path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve the version of %s: %w", mv.Path, err)
+			}
+			mv.Version = info.Version
+		}
+		if seen[mv] {
+			continue
+		}
+		seen[mv] = true
+
+		findings, err := db.Check(mv.Path, mv.Version)
+		if err != nil {
+			return nil, err
+		}
+		if findings == nil {
+			findings = []vuln.Finding{}
+		}
+		reports = append(reports, vulnReport{Path: mv.Path, Version: mv.Version, Findings: findings})
+	}
+
+	return reports, nil
+}
+
+// prints a warning for each affected module among the packages that are
+// about to be installed. The install goes on either way
+func (a *App) warnVulnerable(pkgs []cache.Package, needsInstall func(cache.Package) bool) {
+	if a.getVulnDB().Disabled() {
+		return
+	}
+
+	var pending []cache.Package
+	for _, pkg := range pkgs {
+		if needsInstall(pkg) {
+			pending = append(pending, pkg)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	reports, err := a.checkVulns(pending)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "warning: vulnerability check skipped: %v\n", err)
+		return
+	}
+
+	for _, r := range reports {
+		if len(r.Findings) > 0 {
+			fmt.Fprint(a.stderr, "warning: ")
+			writeVulnReports(a.stderr, []vulnReport{r})
+		}
+	}
+}
+
+// reports the packages about to be installed that the package policy bans,
+// failing when refuse is set
+func (a *App) checkPolicy(pkgs []cache.Package, needsInstall func(cache.Package) bool, refuse bool) error {
+	a.getPolicy().Apply(pkgs)
+
+	var banned []string
+	for _, pkg := range policy.Banned(pkgs) {
+		if !needsInstall(pkg) {
+			continue
+		}
+		banned = append(banned, pkg.ImportPath)
+
+		msg := "banned by the package policy"
+		if pkg.PolicyReason != "" {
+			msg += ": " + pkg.PolicyReason
+		}
+		fmt.Fprintf(a.stderr, "warning: %s is %s\n", pkg.ImportPath, msg)
+	}
+
+	if len(banned) > 0 && refuse {
+		return fmt.Errorf("%s: %w", strings.Join(banned, ", "), ErrBanned)
+	}
+	return nil
+}
+
+// applies the license policy to the packages that are about to be
+// installed, warning about or refusing disallowed licenses
+func (a *App) checkLicenses(pkgs []cache.Package, needsInstall func(cache.Package) bool) error {
+	policy := a.config.LicensePolicy
+	if !policy.Enabled() {
+		return nil
+	}
+
+	var denied []string
+	for _, pkg := range pkgs {
+		if !needsInstall(pkg) {
+			continue
+		}
+
+		id, err := a.pkgManager.DetectLicense(pkg)
+		if err != nil {
+			fmt.Fprintf(a.stderr, "warning: could not detect the license of %s: %v\n", pkg.ImportPath, err)
+			continue
+		}
+		if reason := license.Check(policy, id); reason != "" {
+			denied = append(denied, pkg.ImportPath)
+			fmt.Fprintf(a.stderr, "warning: %s: %s by the license policy\n", pkg.ImportPath, reason)
+		}
+	}
+
+	if len(denied) > 0 && policy.Blocks() {
+		return fmt.Errorf("%s: %w", strings.Join(denied, ", "), ErrLicenseDenied)
+	}
+	return nil
+}
+
+// points out the standard library packages among pkgs, which are never
+// passed to go get, with the import that uses them
+func (a *App) noteStdlib(pkgs []cache.Package) {
+	for _, pkg := range pkgs {
+		if pkg.Stdlib {
+			fmt.Fprintf(a.stderr, "note: %s is in the standard library, add: %s\n", pkg.ImportPath, packages.ImportLines([]string{pkg.ImportPath}))
+		}
+	}
+}
+
+// resolves the --module list against the workspace
+func (a *App) targetModules(list string) ([]packages.Module, error) {
+	if list == "" {
+		return nil, nil
+	}
+
+	project := a.pkgManager.Project()
+	if project == nil {
+		return nil, packages.ErrNoProject
+	}
+
+	var modules []packages.Module
+	for _, name := range strings.Split(list, ",") {
+		mod, ok := project.Module(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("module %q is not part of the workspace", name)
+		}
+		modules = append(modules, mod)
+	}
+
+	return modules, nil
+}
+
+func (a *App) runVersions(args []string) error {
+	fs := a.newFlagSet("versions")
+	asJSON := fs.Bool("json", false, "print versions as JSON")
+	limit := fs.Int("n", 20, "number of versions to show, newest first; 0 shows all")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(a.stderr, "versions: expected exactly one package")
+		return ErrUsage
+	}
+
+	a.pkgManager.ResolveModules([]string{fs.Arg(0)})
+	modulePath := a.pkgManager.ModulePath(fs.Arg(0))
+	client := a.getProxy()
+	versions, err := client.VersionHistory(modulePath)
+	if err != nil {
+		return fmt.Errorf("failed to list versions of %s: %w", modulePath, err)
+	}
+	if *limit > 0 && len(versions) > *limit {
+		versions = versions[:*limit]
+	}
+
+	// release times cost a request each, so only the versions shown get one
+	var untimed []string
+	for _, v := range versions {
+		if v.Time.IsZero() {
+			untimed = append(untimed, v.Version)
+		}
+	}
+	times := client.ReleaseTimes(modulePath, untimed)
+	for i, v := range versions {
+		if t, ok := times[v.Version]; ok {
+			versions[i].Time = t
+		}
+	}
+
+	if *asJSON {
+		return a.writeJSON(versions)
+	}
+
+	for _, v := range versions {
+		released := "unknown"
+		if !v.Time.IsZero() {
+			released = v.Time.Format("2006-01-02")
+		}
+		line := fmt.Sprintf("%s@%s  %s", modulePath, v.Version, released)
+		if v.Retracted {
+			line += "  retracted"
+			if v.Rationale != "" {
+				line += ": " + v.Rationale
+			}
+		}
+		fmt.Fprintln(a.stdout, line)
+	}
+	return nil
+}
+
+func (a *App) runHistory(args []string) error {
+	if len(args) > 0 && args[0] == "clear" {
+		if err := a.history.Clear(); err != nil {
+			return err
+		}
+		fmt.Fprintln(a.stdout, "History cleared")
+		return nil
+	}
+
+	fs := a.newFlagSet("history")
+	asJSON := fs.Bool("json", false, "print entries as JSON")
+	limit := fs.Int("n", 20, "number of entries to show")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	entries, err := a.history.GetRecent(*limit)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return a.writeJSON(entries)
+	}
+
+	for _, entry := range entries {
+		fmt.Fprintf(a.stdout, "%s  %-9s  %s\n",
+			entry.Timestamp.Format("2006-01-02 15:04"), entry.Action, entry.ImportPath)
+	}
+	return nil
+}
+
+func (a *App) runCache(args []string) error {
+	if len(args) != 1 || args[0] != "clear" {
+		fmt.Fprintln(a.stderr, "cache: expected \"clear\"")
+		return ErrUsage
+	}
+
+	if err := a.cache.Clear(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(a.stdout, "Cache cleared successfully")
+	return nil
+}
+
+func (a *App) runShellInit(args []string) error {
+	fs := a.newFlagSet("shell-init")
+	noBind := fs.Bool("no-bind", false, "only define the wrapper functions, without the Ctrl-G binding")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(a.stderr, "shell-init: expected one of bash, zsh, fish")
+		return ErrUsage
+	}
+
+	snippet, err := shell.Init(fs.Arg(0), !*noBind)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "shell-init: %v\n", err)
+		return ErrUsage
+	}
+
+	fmt.Fprint(a.stdout, snippet)
+	return nil
+}
+
+// without a client from New, one is made that reads go env on first use
+func (a *App) getProxy() *proxy.Client {
+	if a.proxy == nil {
+		a.proxy = proxy.NewFromEnv()
+	}
+	return a.proxy
+}
+
+// the package policy is read on first use; without one everything is allowed
+func (a *App) getPolicy() *policy.Policy {
+	if !a.policyLoaded && a.config.Policy != "" {
+		p, err := policy.Load(a.config.Policy, a.config.CacheDir)
+		if err != nil {
+			fmt.Fprintf(a.stderr, "warning: package policy not applied: %v\n", err)
+		}
+		a.policy = p
+	}
+	a.policyLoaded = true
+	return a.policy
+}
+
+func (a *App) getVulnDB() *vuln.Client {
+	if a.vulnDB == nil {
+		a.vulnDB = vuln.New(vuln.Source(a.config.VulnDB))
+	}
+	return a.vulnDB
+}
+
+func (a *App) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	return fs
+}
+
+func (a *App) writeJSON(v interface{}) error {
+	enc := json.NewEncoder(a.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// resolves the modules of pkgs through the proxy, then fills in their
+// install status
+func (a *App) markInstalled(pkgs []cache.Package) []cache.Package {
+	var importPaths []string
+	for _, pkg := range pkgs {
+		importPaths = append(importPaths, pkg.ImportPath)
+	}
+	a.pkgManager.ResolveModules(importPaths)
+	return a.pkgManager.MarkInstalledPackages(pkgs)
+}
+
+// splits "name=path@version" into the import to add and the package
+func parseImportArg(arg string) (imports.Spec, cache.Package) {
+	spec := imports.ParseSpec(arg)
+	pkg := parsePackageArg(spec.Path)
+	spec.Path = pkg.ImportPath
+	return spec, pkg
+}
+
+// splits "path@version" into a package
+func parsePackageArg(arg string) cache.Package {
+	importPath, version, _ := strings.Cut(arg, "@")
+	parts := strings.Split(importPath, "/")
+
+	return cache.Package{
+		Name:       parts[len(parts)-1],
+		ImportPath: importPath,
+		Version:    version,
+		Pinned:     version != "",
+	}
+}
@@ -0,0 +1,532 @@
+package cli
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+	"github.com/MdSadiqMd/gopick/internal/config"
+	"github.com/MdSadiqMd/gopick/internal/history"
+	"github.com/MdSadiqMd/gopick/internal/packages"
+	"github.com/MdSadiqMd/gopick/internal/proxy"
+	"github.com/MdSadiqMd/gopick/internal/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestApp(t *testing.T) (*App, *bytes.Buffer, *bytes.Buffer) {
+	tempDir := t.TempDir()
+
+	cfg := config.DefaultConfig()
+	cfg.CacheDir = filepath.Join(tempDir, "cache")
+	cfg.HistoryFile = filepath.Join(tempDir, ".gopick_history")
+	cfg.GoModCachePath = filepath.Join(tempDir, "mod")
+	cfg.VulnDB = "off"
+
+	c, err := cache.New(cfg.CacheDir, cfg.CacheTTLDays)
+	require.NoError(t, err)
+
+	h, err := history.New(cfg.HistoryFile, cfg.MaxHistoryEntries)
+	require.NoError(t, err)
+
+	var stdout, stderr bytes.Buffer
+	s, err := search.NewCatalog(filepath.Join("testdata", "catalog.json"))
+	require.NoError(t, err)
+
+	app := New(cfg, c, h, packages.New(cfg.GoModCachePath), s, nil, &stdout, &stderr)
+
+	return app, &stdout, &stderr
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	app, _, stderr := newTestApp(t)
+
+	err := app.Run([]string{"frobnicate"})
+	assert.ErrorIs(t, err, ErrUsage)
+	assert.Contains(t, stderr.String(), "unknown command")
+}
+
+func TestRunHelp(t *testing.T) {
+	app, stdout, _ := newTestApp(t)
+
+	err := app.Run([]string{"help"})
+	require.NoError(t, err)
+	assert.Contains(t, stdout.String(), "gopick search")
+}
+
+func TestRunSearchFromCache(t *testing.T) {
+	app, stdout, _ := newTestApp(t)
+
+	err := app.cache.Set("cobra", []cache.Package{
+		{
+			Name:        "cobra",
+			ImportPath:  "github.com/test/cobra",
+			Description: "A Commander for modern Go CLI interactions",
+		},
+	})
+	require.NoError(t, err)
+
+	err = app.Run([]string{"search", "--json", "cobra"})
+	require.NoError(t, err)
+
+	var results []cache.Package
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &results))
+	assert.Len(t, results, 1)
+	assert.Equal(t, "github.com/test/cobra", results[0].ImportPath)
+}
+
+func TestRunSearchMissingQuery(t *testing.T) {
+	app, _, _ := newTestApp(t)
+
+	err := app.Run([]string{"search"})
+	assert.ErrorIs(t, err, ErrUsage)
+}
+
+func TestRunGetPrint(t *testing.T) {
+	app, stdout, _ := newTestApp(t)
+
+	err := app.Run([]string{"get", "--print", "github.com/test/pkg1", "github.com/test/pkg2@v1.2.0"})
+	require.NoError(t, err)
+	assert.Equal(t, "go get github.com/test/pkg1 github.com/test/pkg2@v1.2.0\n", stdout.String())
+}
+
+func TestRunGetStdlib(t *testing.T) {
+	app, stdout, stderr := newTestApp(t)
+	app.pkgManager.SetStdlib([]string{"encoding/json"})
+
+	require.NoError(t, app.Run([]string{"get", "--print", "encoding/json", "github.com/test/pkg1"}))
+	assert.Equal(t, "go get github.com/test/pkg1\n", stdout.String())
+	assert.Contains(t, stderr.String(), `encoding/json is in the standard library, add: import "encoding/json"`)
+
+	stdout.Reset()
+	require.NoError(t, app.Run([]string{"get", "--print", "encoding/json"}))
+	assert.Empty(t, stdout.String())
+}
+
+func TestRunImport(t *testing.T) {
+	app, stdout, stderr := newTestApp(t)
+	file := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(file, []byte("package main\n\nimport \"os\"\n"), 0644))
+
+	require.NoError(t, app.Run([]string{"import", "--file", file, "yaml=gopkg.in/yaml.v3@v3.0.1", "encoding/json"}))
+	assert.Equal(t, file+": import yaml \"gopkg.in/yaml.v3\"\n"+file+": import \"encoding/json\"\n", stdout.String())
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nimport (\n\t\"encoding/json\"\n\t\"os\"\n\n\tyaml \"gopkg.in/yaml.v3\"\n)\n", string(data))
+
+	stdout.Reset()
+	require.NoError(t, app.Run([]string{"import", "--file", file, "os"}))
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr.String(), "already imports")
+
+	t.Setenv("GOPICK_FILE", "")
+	assert.ErrorIs(t, app.Run([]string{"import", "fmt"}), ErrUsage)
+	assert.Error(t, app.Run([]string{"import", "--file", file, "bad path"}))
+}
+
+func TestRunInstallPrint(t *testing.T) {
+	app, stdout, stderr := newTestApp(t)
+	goBin := t.TempDir()
+	app.pkgManager.SetGoBin(goBin)
+	require.NoError(t, os.WriteFile(filepath.Join(goBin, "air"), nil, 0755))
+
+	err := app.Run([]string{"install", "--print", "golang.org/x/tools/cmd/stringer", "github.com/golang/mock/mockgen@1.6.0", "github.com/air-verse/air"})
+	require.NoError(t, err)
+	assert.Equal(t, "go install golang.org/x/tools/cmd/stringer@latest && go install github.com/golang/mock/mockgen@v1.6.0\n", stdout.String())
+
+	err = app.Run([]string{"install"})
+	assert.ErrorIs(t, err, ErrUsage)
+	assert.Contains(t, stderr.String(), "missing command")
+}
+
+func TestRunHistory(t *testing.T) {
+	app, stdout, _ := newTestApp(t)
+
+	require.NoError(t, app.history.Add("testpkg", "github.com/test/testpkg", history.ActionInstalled))
+
+	err := app.Run([]string{"history"})
+	require.NoError(t, err)
+	assert.Contains(t, stdout.String(), "installed")
+	assert.Contains(t, stdout.String(), "github.com/test/testpkg")
+
+	stdout.Reset()
+	err = app.Run([]string{"history", "clear"})
+	require.NoError(t, err)
+
+	entries, err := app.history.GetAll()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRunCacheClear(t *testing.T) {
+	app, stdout, _ := newTestApp(t)
+
+	require.NoError(t, app.cache.Set("query", []cache.Package{{Name: "pkg"}}))
+
+	err := app.Run([]string{"cache", "clear"})
+	require.NoError(t, err)
+	assert.Contains(t, stdout.String(), "Cache cleared")
+
+	_, found := app.cache.Get("query")
+	assert.False(t, found)
+}
+
+func TestParsePackageArg(t *testing.T) {
+	pkg := parsePackageArg("github.com/spf13/cobra@v1.8.0")
+	assert.Equal(t, "cobra", pkg.Name)
+	assert.Equal(t, "github.com/spf13/cobra", pkg.ImportPath)
+	assert.Equal(t, "v1.8.0", pkg.Version)
+	assert.True(t, pkg.Pinned)
+
+	pkg = parsePackageArg("github.com/spf13/viper")
+	assert.Equal(t, "viper", pkg.Name)
+	assert.Empty(t, pkg.Version)
+	assert.False(t, pkg.Pinned)
+}
+
+func TestRunVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github.com/test/pkg/@v/list":
+			fmt.Fprint(w, "v1.0.0\nv1.1.0\n")
+		case "/github.com/test/pkg/@v/v1.0.0.info":
+			fmt.Fprint(w, `{"Version":"v1.0.0","Time":"2023-01-02T00:00:00Z"}`)
+		case "/github.com/test/pkg/@v/v1.1.0.info":
+			fmt.Fprint(w, `{"Version":"v1.1.0","Time":"2024-05-06T00:00:00Z"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	app, stdout, _ := newTestApp(t)
+	app.proxy = proxy.New(proxy.Settings{GoProxy: server.URL})
+
+	err := app.Run([]string{"versions", "github.com/test/pkg"})
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/test/pkg@v1.1.0  2024-05-06\ngithub.com/test/pkg@v1.0.0  2023-01-02\n", stdout.String())
+
+	err = app.Run([]string{"versions", "github.com/test/missing"})
+	assert.Error(t, err)
+}
+
+func TestRunSearchFromProvider(t *testing.T) {
+	app, stdout, _ := newTestApp(t)
+
+	err := app.Run([]string{"search", "--format", "tsv", "toml"})
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/test/toml\tv1.3.2\tfalse\tfalse\tTOML parser and encoder\n", stdout.String())
+
+	// results are cached for the next run
+	_, found := app.cache.Get("toml")
+	assert.True(t, found)
+}
+
+func TestRunSearchFallbackIsNotCached(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	app, stdout, stderr := newTestApp(t)
+	app.searcher = search.NewFallback(search.NewHTTPCatalog(down.URL), app.searcher)
+
+	require.NoError(t, app.Run([]string{"search", "--format", "json", "toml"}))
+	var results []searchResult
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &results))
+	require.Len(t, results, 1)
+	assert.Equal(t, sourceFallback, results[0].Source)
+	assert.False(t, results[0].FromCache)
+
+	// offline answers must not stand in for the providers once they are back
+	_, found := app.cache.Get("toml")
+	assert.False(t, found)
+
+	stdout.Reset()
+	require.NoError(t, app.Run([]string{"search", "toml"}))
+	assert.Contains(t, stderr.String(), "showing offline results")
+}
+
+func TestRunShellInit(t *testing.T) {
+	app, stdout, _ := newTestApp(t)
+
+	err := app.Run([]string{"shell-init", "zsh"})
+	require.NoError(t, err)
+	assert.Contains(t, stdout.String(), "print -z")
+	assert.Contains(t, stdout.String(), "bindkey '^G' __gopick_widget")
+
+	stdout.Reset()
+	err = app.Run([]string{"shell-init", "--no-bind", "bash"})
+	require.NoError(t, err)
+	assert.NotContains(t, stdout.String(), "bind -x '")
+
+	err = app.Run([]string{"shell-init", "tcsh"})
+	assert.ErrorIs(t, err, ErrUsage)
+
+	err = app.Run([]string{"shell-init"})
+	assert.ErrorIs(t, err, ErrUsage)
+}
+
+func TestRunGetModule(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("GOWORK", "")
+
+	files := map[string]string{
+		"go.work":    "go 1.21\n\nuse (\n\t./app\n\t./lib\n)\n",
+		"app/go.mod": "module example.com/app\n\ngo 1.21\n",
+		"lib/go.mod": "module example.com/lib\n\ngo 1.21\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	project, err := packages.LoadProject(filepath.Join(tempDir, "app"))
+	require.NoError(t, err)
+
+	app, stdout, _ := newTestApp(t)
+	app.pkgManager.SetProject(project)
+
+	err = app.Run([]string{"get", "--print", "--module", "example.com/lib", "github.com/test/pkg1"})
+	require.NoError(t, err)
+	assert.Regexp(t, `^cd \S+/lib && go get github.com/test/pkg1\n$`, stdout.String())
+
+	err = app.Run([]string{"get", "--print", "--module", "example.com/missing", "github.com/test/pkg1"})
+	assert.ErrorContains(t, err, "not part of the workspace")
+}
+
+func TestRunTool(t *testing.T) {
+	dir := t.TempDir()
+	goMod := "module example.com/app\n\ngo 1.24\n\ntool (\n\texample.com/tools/cmd/a\n\texample.com/tools/cmd/b\n)\n\nrequire example.com/tools v1.2.0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644))
+
+	project, err := packages.LoadProject(dir)
+	require.NoError(t, err)
+
+	app, stdout, _ := newTestApp(t)
+	app.pkgManager.SetProject(project)
+
+	require.NoError(t, app.Run([]string{"tool"}))
+	assert.Equal(t, "example.com/tools/cmd/a  v1.2.0\nexample.com/tools/cmd/b  v1.2.0\n", stdout.String())
+
+	stdout.Reset()
+	require.NoError(t, app.Run([]string{"tool", "remove", "example.com/tools/cmd/a"}))
+	assert.Equal(t, "Removed tool example.com/tools/cmd/a\n", stdout.String())
+
+	stdout.Reset()
+	require.NoError(t, app.Run([]string{"tool", "list", "--json"}))
+	assert.JSONEq(t, `["example.com/tools/cmd/b"]`, stdout.String())
+
+	assert.ErrorIs(t, app.Run([]string{"tool", "add"}), ErrUsage)
+	assert.ErrorIs(t, app.Run([]string{"tool", "frobnicate"}), ErrUsage)
+}
+
+func TestRunDeps(t *testing.T) {
+	dir := t.TempDir()
+	goMod := "module example.com/app\n\ngo 1.21\n\nrequire (\n\texample.com/b v1.0.0 // indirect\n\texample.com/a v1.2.0\n)\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644))
+
+	project, err := packages.LoadProject(dir)
+	require.NoError(t, err)
+
+	app, stdout, stderr := newTestApp(t)
+	app.pkgManager.SetProject(project)
+
+	require.NoError(t, app.Run([]string{"deps"}))
+	assert.Equal(t, "example.com/a  v1.2.0\nexample.com/b  v1.0.0  // indirect\n", stdout.String())
+
+	err = app.Run([]string{"deps", "remove", "example.com/missing"})
+	assert.ErrorContains(t, err, "is not required by example.com/app")
+
+	assert.ErrorIs(t, app.Run([]string{"deps", "downgrade", "example.com/a"}), ErrUsage)
+	assert.Contains(t, stderr.String(), "expected module@version")
+	assert.ErrorIs(t, app.Run([]string{"deps", "upgrade"}), ErrUsage)
+}
+
+func TestRunOutdated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/a/@latest":
+			fmt.Fprint(w, `{"Version":"v1.3.0"}`)
+		case "/example.com/b/@latest":
+			fmt.Fprint(w, `{"Version":"v1.0.0"}`)
+		case "/example.com/b/v2/@latest":
+			fmt.Fprint(w, `{"Version":"v2.1.0"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	goMod := "module example.com/app\n\ngo 1.21\n\nrequire (\n\texample.com/a v1.2.0\n\texample.com/b v1.0.0 // indirect\n)\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644))
+	project, err := packages.LoadProject(dir)
+	require.NoError(t, err)
+
+	app, stdout, _ := newTestApp(t)
+	app.pkgManager.SetProject(project)
+	app.pkgManager.SetResolver(packages.NewResolver(proxy.New(proxy.Settings{GoProxy: server.URL})))
+
+	require.NoError(t, app.Run([]string{"outdated"}))
+	assert.Equal(t, "example.com/a  v1.2.0 => v1.3.0  minor\nexample.com/b  v1.0.0 => example.com/b/v2 v2.1.0  major // indirect\n", stdout.String())
+
+	stdout.Reset()
+	require.NoError(t, app.Run([]string{"outdated", "--print"}))
+	assert.Equal(t, "go get example.com/a@v1.3.0\n", stdout.String())
+
+	stdout.Reset()
+	require.NoError(t, app.Run([]string{"outdated", "--print", "--major"}))
+	assert.Equal(t, "go get example.com/a@v1.3.0 example.com/b/v2@v2.1.0\n", stdout.String())
+}
+
+func TestRunVuln(t *testing.T) {
+	db := t.TempDir()
+	entry := `{"id": "GO-2024-0001", "aliases": ["CVE-2024-0001"], "summary": "Panic on malformed input",
+		"affected": [{"package": {"name": "example.com/a", "ecosystem": "Go"},
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.2.1"}]}]}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(db, "GO-2024-0001.json"), []byte(entry), 0644))
+
+	app, stdout, stderr := newTestApp(t)
+	app.config.VulnDB = db
+
+	err := app.Run([]string{"vuln", "example.com/a@v1.2.0", "example.com/a/sub@v1.3.0"})
+	assert.ErrorIs(t, err, ErrVulnerable)
+	assert.Equal(t, "example.com/a@v1.2.0: 1 known vulnerability\n"+
+		"  GO-2024-0001 (CVE-2024-0001)  Panic on malformed input  [fixed in v1.2.1]\n"+
+		"example.com/a@v1.3.0: no known vulnerabilities\n", stdout.String())
+
+	stdout.Reset()
+	require.NoError(t, app.Run([]string{"vuln", "--json", "example.com/a@v1.2.1"}))
+	var reports []vulnReport
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &reports))
+	require.Len(t, reports, 1)
+	assert.Empty(t, reports[0].Findings)
+
+	stdout.Reset()
+	require.NoError(t, app.Run([]string{"get", "--print", "example.com/a@v1.0.0"}))
+	assert.Equal(t, "go get example.com/a@v1.0.0\n", stdout.String())
+	assert.Contains(t, stderr.String(), "warning: example.com/a@v1.0.0: 1 known vulnerability")
+}
+
+func TestRunVulnStdlib(t *testing.T) {
+	db := t.TempDir()
+	entry := `{"id": "GO-2024-0002", "summary": "Unbounded memory use in net/http",
+		"affected": [{"package": {"name": "stdlib", "ecosystem": "Go"},
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.22.2"}]}]}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(db, "GO-2024-0002.json"), []byte(entry), 0644))
+
+	app, stdout, _ := newTestApp(t)
+	app.config.VulnDB = db
+	app.pkgManager.SetStdlib([]string{"net/http", "encoding/json"})
+	app.pkgManager.SetGoVersion("v1.22.1")
+
+	// both packages ship with the same toolchain, so there is one report
+	err := app.Run([]string{"vuln", "net/http", "encoding/json"})
+	assert.ErrorIs(t, err, ErrVulnerable)
+	assert.Equal(t, "stdlib@v1.22.1: 1 known vulnerability\n"+
+		"  GO-2024-0002  Unbounded memory use in net/http  [fixed in v1.22.2]\n", stdout.String())
+}
+
+func TestRunGetLicensePolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/gpl/@v/v1.0.0.zip":
+			zw := zip.NewWriter(w)
+			f, _ := zw.Create("example.com/gpl@v1.0.0/COPYING")
+			f.Write([]byte("GNU GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007"))
+			zw.Close()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	app, stdout, stderr := newTestApp(t)
+	app.pkgManager.SetResolver(packages.NewResolver(proxy.New(proxy.Settings{GoProxy: server.URL})))
+	app.config.LicensePolicy = config.LicensePolicy{Allow: []string{"MIT", "BSD-*"}}
+
+	require.NoError(t, app.Run([]string{"get", "--print", "example.com/gpl@v1.0.0"}))
+	assert.Equal(t, "go get example.com/gpl@v1.0.0\n", stdout.String())
+	assert.Contains(t, stderr.String(), "warning: example.com/gpl: GPL-3.0 is not allowed by the license policy")
+
+	stdout.Reset()
+	app.config.LicensePolicy.Action = config.LicenseActionBlock
+	err := app.Run([]string{"get", "--print", "example.com/gpl@v1.0.0"})
+	assert.ErrorIs(t, err, ErrLicenseDenied)
+	assert.Empty(t, stdout.String())
+}
+
+func TestRunPackagePolicy(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(policyFile, []byte(`{"deny": [
+		{"module": "github.com/test/toml", "reason": "unmaintained", "replacement": "github.com/test/toml2"}
+	]}`), 0644))
+
+	app, stdout, stderr := newTestApp(t)
+	app.config.Policy = "file://" + filepath.ToSlash(policyFile)
+
+	require.NoError(t, app.Run([]string{"search", "toml"}))
+	assert.Contains(t, stdout.String(), "github.com/test/toml v1.3.2 [banned: unmaintained; use github.com/test/toml2 instead]")
+
+	stdout.Reset()
+	err := app.Run([]string{"get", "--print", "github.com/test/toml"})
+	assert.ErrorIs(t, err, ErrBanned)
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr.String(), "warning: github.com/test/toml is banned by the package policy: unmaintained")
+
+	require.NoError(t, app.Run([]string{"get", "--print", "--force", "github.com/test/toml"}))
+	assert.Equal(t, "go get github.com/test/toml\n", stdout.String())
+
+	err = app.Run([]string{"get", "github.com/test/toml"})
+	assert.ErrorIs(t, err, ErrBanned)
+}
+
+func TestRunSearchSort(t *testing.T) {
+	app, stdout, _ := newTestApp(t)
+	require.NoError(t, app.cache.Set("lib", []cache.Package{
+		{Name: "a", ImportPath: "example.com/a", ImportedBy: 10},
+		{Name: "b", ImportPath: "example.com/b", ImportedBy: 200},
+		{Name: "c", ImportPath: "example.com/c"},
+	}))
+
+	require.NoError(t, app.Run([]string{"search", "--format", "tsv", "--sort", "imported-by", "lib"}))
+	assert.Equal(t, "example.com/b\t\tfalse\ttrue\t\nexample.com/a\t\tfalse\ttrue\t\nexample.com/c\t\tfalse\ttrue\t\n", stdout.String())
+
+	// installs from the history lift a result
+	require.NoError(t, app.history.Add("c", "example.com/c", history.ActionInstalled))
+	stdout.Reset()
+	require.NoError(t, app.Run([]string{"search", "--format", "tsv", "lib"}))
+	assert.Equal(t, "example.com/c\t\tfalse\ttrue\t\nexample.com/a\t\tfalse\ttrue\t\nexample.com/b\t\tfalse\ttrue\t\n", stdout.String())
+
+	assert.ErrorIs(t, app.Run([]string{"search", "--sort", "downloads", "lib"}), ErrUsage)
+}
+
+func TestRunSearchFilters(t *testing.T) {
+	app, stdout, _ := newTestApp(t)
+	require.NoError(t, app.cache.Set("lib", []cache.Package{
+		{Name: "a", ImportPath: "github.com/x/a", License: "MIT"},
+		{Name: "b", ImportPath: "gitlab.com/x/b", License: "Apache-2.0", Description: "deprecated"},
+		{Name: "c", ImportPath: "github.com/x/c", License: "BSD-3-Clause"},
+	}))
+
+	// local filters are not part of the cache key, so the cached results are used
+	require.NoError(t, app.Run([]string{"search", "--format", "tsv", "lib", "host:github.com,gitlab.com", "-license:mit"}))
+	assert.Equal(t, "gitlab.com/x/b\t\tfalse\ttrue\tdeprecated\ngithub.com/x/c\t\tfalse\ttrue\t\n", stdout.String())
+
+	stdout.Reset()
+	require.NoError(t, app.Run([]string{"search", "--format", "tsv", "--", "-deprecated", "lib"}))
+	assert.Equal(t, "github.com/x/a\t\tfalse\ttrue\t\ngithub.com/x/c\t\tfalse\ttrue\t\n", stdout.String())
+
+	assert.ErrorIs(t, app.Run([]string{"search", "lib", "updated:soon"}), ErrUsage)
+	assert.ErrorIs(t, app.Run([]string{"search", "std:false"}), ErrUsage)
+}
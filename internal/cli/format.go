@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+	"github.com/MdSadiqMd/gopick/internal/packages"
+)
+
+const (
+	FormatPlain  = "plain"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatTSV    = "tsv"
+)
+
+// searchResult is the machine-readable form of a search hit. Unlike
+// cache.Package it never omits fields, so consumers can rely on them
+type searchResult struct {
+	Name        string `json:"name"`
+	ImportPath  string `json:"import_path"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+	IsInstalled bool   `json:"is_installed"`
+	Stdlib      bool   `json:"stdlib"`
+	FromCache   bool   `json:"from_cache"`
+	// cache, providers or fallback, the offline providers standing in for
+	// unreachable ones
+	Source string `json:"source"`
+
+	InModule        bool   `json:"in_module"`
+	RequiredVersion string `json:"required_version"`
+	Indirect        bool   `json:"indirect"`
+	InModCache      bool   `json:"in_mod_cache"`
+
+	Banned       bool   `json:"banned"`
+	PolicyReason string `json:"policy_reason"`
+
+	// search filters the package lacked the data for
+	Unverified []string `json:"unverified"`
+}
+
+func newSearchResults(pkgs []cache.Package, source string) []searchResult {
+	results := make([]searchResult, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		results = append(results, searchResult{
+			Name:        pkg.Name,
+			ImportPath:  pkg.ImportPath,
+			Description: pkg.Description,
+			Version:     pkg.Version,
+			IsInstalled: pkg.IsInstalled,
+			Stdlib:      pkg.Stdlib,
+			FromCache:   source == sourceCache,
+			Source:      source,
+
+			InModule:        pkg.InModule,
+			RequiredVersion: pkg.RequiredVersion,
+			Indirect:        pkg.Indirect,
+			InModCache:      pkg.InModCache,
+
+			Banned:       pkg.Banned,
+			PolicyReason: pkg.PolicyReason,
+
+			Unverified: append([]string{}, pkg.Unverified...),
+		})
+	}
+	return results
+}
+
+func validFormat(format string) bool {
+	switch format {
+	case FormatPlain, FormatJSON, FormatNDJSON, FormatTSV:
+		return true
+	}
+	return false
+}
+
+// writes search results to w in the requested format
+func writeSearchResults(w io.Writer, format string, pkgs []cache.Package, source string) error {
+	results := newSearchResults(pkgs, source)
+
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, result := range results {
+			if err := enc.Encode(result); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case FormatTSV:
+		for _, result := range results {
+			fields := []string{
+				result.ImportPath,
+				result.Version,
+				strconv.FormatBool(result.IsInstalled),
+				strconv.FormatBool(result.FromCache),
+				tsvEscape(result.Description),
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		for _, pkg := range pkgs {
+			if _, err := fmt.Fprintln(w, formatPackage(pkg)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// keeps a field on a single TSV column
+func tsvEscape(s string) string {
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+func formatPackage(pkg cache.Package) string {
+	line := pkg.ImportPath
+	if pkg.Version != "" {
+		line += " " + pkg.Version
+	}
+	switch {
+	case pkg.Stdlib:
+		line += " (stdlib)"
+	case pkg.InModule && pkg.RequiredVersion != "":
+		line += " (in module " + pkg.RequiredVersion + ")"
+	case pkg.InModule:
+		line += " (in module)"
+	case pkg.InModCache:
+		line += " (mod cache)"
+	case pkg.IsInstalled:
+		line += " (installed)"
+	}
+	if pkg.Banned {
+		line += " [banned"
+		if pkg.PolicyReason != "" {
+			line += ": " + pkg.PolicyReason
+		}
+		line += "]"
+	}
+	if len(pkg.Unverified) > 0 {
+		line += " [unverified: " + strings.Join(pkg.Unverified, ", ") + "]"
+	}
+	if pkg.Description != "" {
+		line += "\n    " + pkg.Description
+	}
+	return line
+}
+
+// prints the requirement changes of a dry run followed by the go.mod diff
+func writePreview(w io.Writer, preview *packages.Preview) {
+	if len(preview.Changes) == 0 {
+		fmt.Fprintln(w, "go.mod would not change")
+		return
+	}
+
+	for _, c := range preview.Changes {
+		line := ""
+		switch c.Kind {
+		case packages.ChangeAdded:
+			line = fmt.Sprintf("+ %s %s", c.Path, c.NewVersion)
+		case packages.ChangeRemoved:
+			line = fmt.Sprintf("- %s %s", c.Path, c.OldVersion)
+		default:
+			line = fmt.Sprintf("~ %s %s => %s (%s)", c.Path, c.OldVersion, c.NewVersion, c.Kind)
+		}
+		if c.Indirect {
+			line += " // indirect"
+		}
+		if !c.Requested && c.Kind != packages.ChangeAdded {
+			line += "  [transitive]"
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	if preview.SumAdded > 0 {
+		fmt.Fprintf(w, "go.sum: %d new line(s)\n", preview.SumAdded)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprint(w, preview.Diff)
+}
+
+// prints one update per line, aligning the module paths
+func writeOutdated(w io.Writer, updates []packages.Update) {
+	if len(updates) == 0 {
+		fmt.Fprintln(w, "All requirements are up to date")
+		return
+	}
+
+	width := 0
+	for _, u := range updates {
+		if len(u.Path) > width {
+			width = len(u.Path)
+		}
+	}
+
+	for _, u := range updates {
+		target := u.NewVersion
+		if u.NewPath != u.Path {
+			target = u.NewPath + " " + u.NewVersion
+		}
+		line := fmt.Sprintf("%-*s  %s => %s  %s", width, u.Path, u.Version, target, u.Kind)
+		if u.Indirect {
+			line += " // indirect"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// prints each module version with the vulnerabilities that affect it
+func writeVulnReports(w io.Writer, reports []vulnReport) {
+	for _, r := range reports {
+		switch len(r.Findings) {
+		case 0:
+			fmt.Fprintf(w, "%s@%s: no known vulnerabilities\n", r.Path, r.Version)
+			continue
+		case 1:
+			fmt.Fprintf(w, "%s@%s: 1 known vulnerability\n", r.Path, r.Version)
+		default:
+			fmt.Fprintf(w, "%s@%s: %d known vulnerabilities\n", r.Path, r.Version, len(r.Findings))
+		}
+
+		for _, f := range r.Findings {
+			line := "  " + f.ID
+			if len(f.Aliases) > 0 {
+				line += " (" + strings.Join(f.Aliases, ", ") + ")"
+			}
+			if f.Summary != "" {
+				line += "  " + f.Summary
+			}
+			if f.Fixed != "" {
+				line += "  [fixed in " + f.Fixed + "]"
+			} else {
+				line += "  [no fix]"
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+	"github.com/MdSadiqMd/gopick/internal/packages"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var formatTestPackages = []cache.Package{
+	{
+		Name:        "cobra",
+		ImportPath:  "github.com/spf13/cobra",
+		Description: "A Commander\tfor modern Go CLI interactions",
+		Version:     "v1.8.0",
+		IsInstalled: true,
+	},
+	{
+		Name:       "viper",
+		ImportPath: "github.com/spf13/viper",
+	},
+}
+
+func TestWriteSearchResultsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeSearchResults(&buf, FormatJSON, formatTestPackages, sourceCache)
+	require.NoError(t, err)
+
+	var results []map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &results))
+	require.Len(t, results, 2)
+
+	assert.Equal(t, true, results[0]["is_installed"])
+	assert.Equal(t, true, results[0]["from_cache"])
+	assert.Equal(t, "cache", results[0]["source"])
+	// zero values must still be present
+	assert.Equal(t, false, results[1]["is_installed"])
+	assert.Equal(t, "", results[1]["version"])
+}
+
+func TestWriteSearchResultsNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeSearchResults(&buf, FormatNDJSON, formatTestPackages, sourceFallback)
+	require.NoError(t, err)
+
+	scanner := bufio.NewScanner(&buf)
+	var lines int
+	for scanner.Scan() {
+		var result searchResult
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &result))
+		assert.Equal(t, formatTestPackages[lines].ImportPath, result.ImportPath)
+		assert.False(t, result.FromCache)
+		assert.Equal(t, sourceFallback, result.Source)
+		lines++
+	}
+	assert.Equal(t, 2, lines)
+}
+
+func TestWriteSearchResultsTSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeSearchResults(&buf, FormatTSV, formatTestPackages, sourceProviders)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	fields := strings.Split(lines[0], "\t")
+	assert.Equal(t, []string{
+		"github.com/spf13/cobra",
+		"v1.8.0",
+		"true",
+		"false",
+		"A Commander for modern Go CLI interactions",
+	}, fields)
+}
+
+func TestWriteSearchResultsPlain(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeSearchResults(&buf, FormatPlain, formatTestPackages, sourceProviders)
+	require.NoError(t, err)
+
+	assert.Contains(t, buf.String(), "github.com/spf13/cobra v1.8.0 (installed)")
+	assert.Contains(t, buf.String(), "github.com/spf13/viper\n")
+}
+
+func TestRunSearchUnknownFormat(t *testing.T) {
+	app, _, stderr := newTestApp(t)
+
+	err := app.Run([]string{"search", "--format", "xml", "cobra"})
+	assert.ErrorIs(t, err, ErrUsage)
+	assert.Contains(t, stderr.String(), "unknown format")
+}
+
+func TestWritePreview(t *testing.T) {
+	var buf bytes.Buffer
+	writePreview(&buf, &packages.Preview{
+		Changes: []packages.Change{
+			{Path: "example.com/lib", Kind: packages.ChangeAdded, NewVersion: "v1.0.0", Requested: true},
+			{Path: "example.com/shared", Kind: packages.ChangeUpgraded, OldVersion: "v1.0.0", NewVersion: "v1.1.0", Indirect: true},
+		},
+		Diff:     "--- go.mod\n+++ go.mod\n",
+		SumAdded: 4,
+	})
+
+	assert.Equal(t, "+ example.com/lib v1.0.0\n"+
+		"~ example.com/shared v1.0.0 => v1.1.0 (upgraded) // indirect  [transitive]\n"+
+		"go.sum: 4 new line(s)\n\n"+
+		"--- go.mod\n+++ go.mod\n", buf.String())
+
+	buf.Reset()
+	writePreview(&buf, &packages.Preview{})
+	assert.Equal(t, "go.mod would not change\n", buf.String())
+}
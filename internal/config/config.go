@@ -18,8 +18,55 @@ type Config struct {
 	DefaultAction     string `json:"default_action"`
 	SearchDebounceMS  int    `json:"search_debounce_ms"`
 	GoModCachePath    string `json:"gomodcache_path"`
+	// "gomod" checks the current go.mod/go.work, "modcache" only probes GOMODCACHE
+	InstallStatus string `json:"install_status"`
+	// search providers, queried in order; results are merged
+	Providers []Provider `json:"providers"`
+	// used when the providers above fail, e.g. offline; null means the module cache
+	FallbackProviders []Provider `json:"fallback_providers"`
+	// Go vulnerability database: a URL, a file:// URL or a local mirror
+	// directory; "off" disables the check. GOVULNDB takes precedence
+	VulnDB string `json:"vuln_db"`
+	// go.mod files (or directories, glob patterns) of the team; modules they
+	// require are ranked higher in search results
+	TeamGoMods []string `json:"team_gomods,omitempty"`
+	// package policy listing banned modules: a path, a file:// or an http(s) URL
+	Policy string `json:"policy,omitempty"`
+	// licenses (SPDX identifiers, * wildcards allowed) that may be installed
+	LicensePolicy LicensePolicy `json:"license_policy"`
 }
 
+type LicensePolicy struct {
+	// when set, only these licenses are allowed
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+	// "warn" flags disallowed licenses, "block" refuses to install them
+	Action string `json:"action,omitempty"`
+}
+
+type Provider struct {
+	Type string `json:"type"`
+	URL  string `json:"url,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+const (
+	InstallStatusGoMod    = "gomod"
+	InstallStatusModCache = "modcache"
+)
+
+const (
+	LicenseActionWarn  = "warn"
+	LicenseActionBlock = "block"
+)
+
+const (
+	ProviderPkgGoDev = "pkggodev"
+	ProviderModCache = "modcache"
+	ProviderCatalog  = "catalog"
+	ProviderHTTP     = "http"
+)
+
 func DefaultConfig() *Config {
 	homeDir, _ := os.UserHomeDir()
 	configDir := filepath.Join(homeDir, ".config", "gopick")
@@ -35,6 +82,10 @@ func DefaultConfig() *Config {
 		DefaultAction:     "command",
 		SearchDebounceMS:  300,
 		GoModCachePath:    goModCache,
+		InstallStatus:     InstallStatusGoMod,
+		Providers:         []Provider{{Type: ProviderPkgGoDev}},
+		FallbackProviders: []Provider{{Type: ProviderModCache}},
+		VulnDB:            "https://vuln.go.dev",
 	}
 }
 
@@ -116,6 +167,17 @@ func (c *Config) expandPaths() {
 	c.CacheDir = expandPath(c.CacheDir, homeDir)
 	c.HistoryFile = expandPath(c.HistoryFile, homeDir)
 	c.GoModCachePath = expandPath(c.GoModCachePath, homeDir)
+	c.VulnDB = expandPath(c.VulnDB, homeDir)
+	c.Policy = expandPath(c.Policy, homeDir)
+	for i := range c.TeamGoMods {
+		c.TeamGoMods[i] = expandPath(c.TeamGoMods[i], homeDir)
+	}
+	for i := range c.Providers {
+		c.Providers[i].Path = expandPath(c.Providers[i].Path, homeDir)
+	}
+	for i := range c.FallbackProviders {
+		c.FallbackProviders[i].Path = expandPath(c.FallbackProviders[i].Path, homeDir)
+	}
 }
 
 // creates necessary directories
@@ -169,6 +231,16 @@ func getGoModCachePath() string {
 	return filepath.Join(homeDir, "go", "pkg", "mod")
 }
 
+// reports whether any license is restricted
+func (p LicensePolicy) Enabled() bool {
+	return len(p.Allow) > 0 || len(p.Deny) > 0
+}
+
+// reports whether disallowed licenses stop an install rather than warn
+func (p LicensePolicy) Blocks() bool {
+	return p.Action == LicenseActionBlock
+}
+
 func (c *Config) GetDebounceTime() time.Duration {
 	return time.Duration(c.SearchDebounceMS) * time.Millisecond
 }
@@ -18,6 +18,10 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, 1000, cfg.MaxHistoryEntries)
 	assert.Equal(t, "command", cfg.DefaultAction)
 	assert.Equal(t, 300, cfg.SearchDebounceMS)
+	assert.Equal(t, InstallStatusGoMod, cfg.InstallStatus)
+	assert.Equal(t, []Provider{{Type: ProviderPkgGoDev}}, cfg.Providers)
+	assert.Equal(t, []Provider{{Type: ProviderModCache}}, cfg.FallbackProviders)
+	assert.Equal(t, "https://vuln.go.dev", cfg.VulnDB)
 	assert.NotEmpty(t, cfg.CacheDir)
 	assert.NotEmpty(t, cfg.HistoryFile)
 }
@@ -12,8 +12,11 @@ import (
 type ActionType string
 
 const (
-	ActionViewed    ActionType = "viewed"
-	ActionInstalled ActionType = "installed"
+	ActionViewed     ActionType = "viewed"
+	ActionInstalled  ActionType = "installed"
+	ActionRemoved    ActionType = "removed"
+	ActionUpgraded   ActionType = "upgraded"
+	ActionDowngraded ActionType = "downgraded"
 )
 
 type Entry struct {
@@ -0,0 +1,274 @@
+package imports
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// FileEnv names the variable an editor sets to the file being edited, so
+// picked packages can be imported into it
+const FileEnv = "GOPICK_FILE"
+
+// Spec is one import to add
+type Spec struct {
+	// empty for the package name, "_" for a blank import, "." for a dot import
+	Name string
+	Path string
+}
+
+// parses "path" or "name=path", e.g. "_=github.com/lib/pq"
+func ParseSpec(arg string) Spec {
+	if name, path, ok := strings.Cut(arg, "="); ok {
+		return Spec{Name: name, Path: path}
+	}
+	return Spec{Path: arg}
+}
+
+// returns the spec as written in an import block
+func (s Spec) String() string {
+	if s.Name == "" {
+		return strconv.Quote(s.Path)
+	}
+	return s.Name + " " + strconv.Quote(s.Path)
+}
+
+func (s Spec) validate() error {
+	if err := module.CheckImportPath(s.Path); err != nil {
+		return err
+	}
+	if s.Name != "" && s.Name != "_" && s.Name != "." && !token.IsIdentifier(s.Name) {
+		return fmt.Errorf("invalid import name %q", s.Name)
+	}
+	return nil
+}
+
+// reports whether the first element of path has no dot, which is how the
+// standard library is told apart when nothing better is known
+func heuristicStd(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// adds specs to the imports of the Go source src and returns it formatted,
+// along with the specs that were not imported yet. Standard library imports
+// join the first group of the import block and the rest the last group
+// holding other imports, as goimports lays them out. isStd tells the two
+// apart; nil means a path without a dot in its first element
+func Add(filename string, src []byte, specs []Spec, isStd func(string) bool) ([]byte, []Spec, error) {
+	if isStd == nil {
+		isStd = heuristicStd
+	}
+
+	var added []Spec
+	for _, spec := range specs {
+		if err := spec.validate(); err != nil {
+			return nil, nil, err
+		}
+
+		// reparse after each insertion so positions stay right
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+		if err != nil {
+			return nil, nil, err
+		}
+		if imported(f, spec) {
+			continue
+		}
+
+		src = insert(fset, f, src, spec, isStd)
+		added = append(added, spec)
+	}
+
+	if len(added) == 0 {
+		return src, nil, nil
+	}
+
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to format %s: %w", filename, err)
+	}
+	return formatted, added, nil
+}
+
+// rewrites the imports of the Go file at path, keeping its permissions
+func AddToFile(path string, specs []Spec, isStd func(string) bool) ([]Spec, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	out, added, err := Add(path, src, specs, isStd)
+	if err != nil || len(added) == 0 {
+		return nil, err
+	}
+
+	if err := os.WriteFile(path, out, info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return added, nil
+}
+
+// reports whether f already has an import that does what spec asks for. A
+// blank import is covered by any import of the path
+func imported(f *ast.File, spec Spec) bool {
+	for _, is := range f.Imports {
+		path, _ := strconv.Unquote(is.Path.Value)
+		if path != spec.Path {
+			continue
+		}
+
+		name := ""
+		if is.Name != nil {
+			name = is.Name.Name
+		}
+		if name == spec.Name || spec.Name == "_" {
+			return true
+		}
+	}
+	return false
+}
+
+var blankLineRe = regexp.MustCompile(`\n[ \t]*\n`)
+
+func insert(fset *token.FileSet, f *ast.File, src []byte, spec Spec, isStd func(string) bool) []byte {
+	std := isStd(spec.Path)
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+
+	// import "C" carries the cgo preamble and has to stay on its own
+	var decl, last *ast.GenDecl
+	for _, d := range f.Decls {
+		gd, ok := d.(*ast.GenDecl)
+		if !ok || gd.Tok != token.IMPORT {
+			continue
+		}
+		last = gd
+		if decl == nil && !isCgo(gd) {
+			decl = gd
+		}
+	}
+
+	switch {
+	case decl == nil:
+		after := f.Name.End()
+		if last != nil {
+			after = last.End()
+		}
+		return splice(src, lineEnd(src, offset(after)), "\n\nimport "+spec.String())
+
+	case !decl.Lparen.IsValid():
+		// a lone import "x" becomes a block
+		existing := decl.Specs[0].(*ast.ImportSpec)
+		start, end := offset(existing.Pos()), lineEnd(src, offset(existing.End()))
+		first, second := string(src[start:end]), spec.String()
+		sep := "\n\t"
+		if isStd(importPath(existing)) != std {
+			sep = "\n\n\t"
+			if std {
+				first, second = second, first
+			}
+		}
+		out := append([]byte{}, src[:offset(decl.Pos())]...)
+		out = append(out, "import (\n\t"+first+sep+second+"\n)"...)
+		return append(out, src[end:]...)
+	}
+
+	specs := decl.Specs
+	if len(specs) == 0 {
+		return splice(src, offset(decl.Lparen)+1, "\n\t"+spec.String()+"\n")
+	}
+
+	// split the block into groups at blank lines
+	var groups [][]*ast.ImportSpec
+	for i, s := range specs {
+		is := s.(*ast.ImportSpec)
+		if i == 0 || blankLineRe.Match(src[offset(specs[i-1].End()):specStart(fset, is)]) {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], is)
+	}
+
+	target := -1
+	for i, g := range groups {
+		if allStd(g, isStd) == std {
+			target = i
+			if std {
+				break
+			}
+		}
+	}
+
+	rparen := offset(decl.Rparen)
+	switch {
+	case target >= 0:
+		g := groups[target]
+		at := min(lineEnd(src, offset(g[len(g)-1].End())), rparen)
+		return splice(src, at, "\n\t"+spec.String())
+	case std:
+		at := max(lineStart(src, specStart(fset, groups[0][0])), offset(decl.Lparen)+1)
+		return splice(src, at, "\t"+spec.String()+"\n\n")
+	default:
+		g := groups[len(groups)-1]
+		at := min(lineEnd(src, offset(g[len(g)-1].End())), rparen)
+		return splice(src, at, "\n\n\t"+spec.String())
+	}
+}
+
+func isCgo(gd *ast.GenDecl) bool {
+	return len(gd.Specs) == 1 && importPath(gd.Specs[0].(*ast.ImportSpec)) == "C"
+}
+
+func importPath(is *ast.ImportSpec) string {
+	path, _ := strconv.Unquote(is.Path.Value)
+	return path
+}
+
+func allStd(group []*ast.ImportSpec, isStd func(string) bool) bool {
+	for _, is := range group {
+		if !isStd(importPath(is)) {
+			return false
+		}
+	}
+	return true
+}
+
+// returns where the spec begins, including the comment above it
+func specStart(fset *token.FileSet, is *ast.ImportSpec) int {
+	if is.Doc != nil {
+		return fset.Position(is.Doc.Pos()).Offset
+	}
+	return fset.Position(is.Pos()).Offset
+}
+
+// returns the offset of the newline ending the line at offset, so trailing
+// comments stay with their line
+func lineEnd(src []byte, offset int) int {
+	if i := bytes.IndexByte(src[offset:], '\n'); i >= 0 {
+		return offset + i
+	}
+	return len(src)
+}
+
+func lineStart(src []byte, offset int) int {
+	return bytes.LastIndexByte(src[:offset], '\n') + 1
+}
+
+func splice(src []byte, at int, text string) []byte {
+	out := make([]byte, 0, len(src)+len(text))
+	out = append(out, src[:at]...)
+	out = append(out, text...)
+	return append(out, src[at:]...)
+}
@@ -0,0 +1,173 @@
+package imports
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		specs []Spec
+		want  string
+	}{
+		{
+			name:  "no imports",
+			src:   "package main // the app\n\nfunc main() {}\n",
+			specs: []Spec{{Path: "fmt"}},
+			want:  "package main // the app\n\nimport \"fmt\"\n\nfunc main() {}\n",
+		},
+		{
+			name:  "single import becomes a group",
+			src:   "package main\n\nimport \"os\" // exit codes\n\nfunc main() {}\n",
+			specs: []Spec{{Path: "fmt"}},
+			want:  "package main\n\nimport (\n\t\"fmt\"\n\t\"os\" // exit codes\n)\n\nfunc main() {}\n",
+		},
+		{
+			name:  "third party after a lone stdlib import",
+			src:   "package main\n\nimport \"os\"\n",
+			specs: []Spec{{Name: "yaml", Path: "gopkg.in/yaml.v3"}},
+			want:  "package main\n\nimport (\n\t\"os\"\n\n\tyaml \"gopkg.in/yaml.v3\"\n)\n",
+		},
+		{
+			name: "grouped by kind",
+			src: `package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+`,
+			specs: []Spec{{Path: "github.com/gin-gonic/gin"}, {Path: "encoding/json"}, {Name: "_", Path: "github.com/lib/pq"}},
+			want: `package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/lib/pq"
+	"github.com/spf13/cobra"
+)
+`,
+		},
+		{
+			name: "new groups",
+			src: `package main
+
+import (
+	// for the config
+	"gopkg.in/yaml.v3"
+)
+`,
+			specs: []Spec{{Path: "fmt"}},
+			want: `package main
+
+import (
+	"fmt"
+
+	// for the config
+	"gopkg.in/yaml.v3"
+)
+`,
+		},
+		{
+			name: "third party group after stdlib only block",
+			src: `package main
+
+import (
+	"fmt"
+)
+`,
+			specs: []Spec{{Name: ".", Path: "github.com/onsi/gomega"}},
+			want: `package main
+
+import (
+	"fmt"
+
+	. "github.com/onsi/gomega"
+)
+`,
+		},
+		{
+			name: "cgo import stays alone",
+			src: `package main
+
+// #include <stdio.h>
+import "C"
+`,
+			specs: []Spec{{Path: "unsafe"}},
+			want: `package main
+
+// #include <stdio.h>
+import "C"
+
+import "unsafe"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, added, err := Add("main.go", []byte(tt.src), tt.specs, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(out))
+			assert.Equal(t, tt.specs, added)
+		})
+	}
+}
+
+func TestAddSkipsExistingImports(t *testing.T) {
+	src := "package main\n\nimport (\n\tyaml \"gopkg.in/yaml.v3\"\n\t\"fmt\"\n)\n"
+
+	out, added, err := Add("main.go", []byte(src), []Spec{{Path: "fmt"}, {Name: "_", Path: "gopkg.in/yaml.v3"}}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, added)
+	assert.Equal(t, src, string(out), "nothing is reformatted")
+
+	// a different name is a different import
+	_, added, err = Add("main.go", []byte(src), []Spec{{Path: "gopkg.in/yaml.v3"}}, nil)
+	require.NoError(t, err)
+	assert.Len(t, added, 1)
+}
+
+func TestAddUsesIsStd(t *testing.T) {
+	src := "package main\n\nimport (\n\t\"fmt\"\n\n\t\"github.com/spf13/cobra\"\n)\n"
+	isStd := func(path string) bool { return path == "fmt" }
+
+	out, _, err := Add("main.go", []byte(src), []Spec{{Path: "myapp/internal/db"}}, isStd)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nimport (\n\t\"fmt\"\n\n\t\"github.com/spf13/cobra\"\n\t\"myapp/internal/db\"\n)\n", string(out))
+}
+
+func TestAddErrors(t *testing.T) {
+	_, _, err := Add("main.go", []byte("package main\n"), []Spec{{Path: "bad path"}}, nil)
+	assert.Error(t, err)
+	_, _, err = Add("main.go", []byte("package main\n"), []Spec{{Name: "1x", Path: "fmt"}}, nil)
+	assert.Error(t, err)
+	_, _, err = Add("main.go", []byte("not go"), []Spec{{Path: "fmt"}}, nil)
+	assert.Error(t, err)
+}
+
+func TestAddToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0600))
+
+	added, err := AddToFile(path, []Spec{ParseSpec("_=github.com/lib/pq")}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []Spec{{Name: "_", Path: "github.com/lib/pq"}}, added)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nimport _ \"github.com/lib/pq\"\n", string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
@@ -0,0 +1,131 @@
+package license
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/MdSadiqMd/gopick/internal/config"
+)
+
+const (
+	// reported when a module ships no license file
+	None = "None"
+	// reported when a license file matches none of the known texts
+	Unknown = "Unknown"
+)
+
+var spdxTag = regexp.MustCompile(`SPDX-License-Identifier:\s*([A-Za-z0-9.+-]+)`)
+
+// rule identifies a license by phrases that must all occur in its text
+type rule struct {
+	id      string
+	phrases []string
+}
+
+// more specific texts come first: the LGPL and AGPL mention the GPL, and
+// the 3-clause BSD text contains the 2-clause one
+var rules = []rule{
+	{"AGPL-3.0", []string{"gnu affero general public license", "version 3"}},
+	{"LGPL-3.0", []string{"gnu lesser general public license", "version 3"}},
+	{"LGPL-2.1", []string{"gnu lesser general public license", "version 2.1"}},
+	{"GPL-3.0", []string{"gnu general public license", "version 3"}},
+	{"GPL-2.0", []string{"gnu general public license", "version 2"}},
+	{"Apache-2.0", []string{"apache license", "version 2.0"}},
+	{"MPL-2.0", []string{"mozilla public license", "2.0"}},
+	{"EPL-2.0", []string{"eclipse public license", "2.0"}},
+	{"BSL-1.0", []string{"boost software license"}},
+	{"Unlicense", []string{"free and unencumbered software released into the public domain"}},
+	{"CC0-1.0", []string{"cc0 1.0 universal"}},
+	{"MIT", []string{"permission is hereby granted, free of charge"}},
+	{"ISC", []string{"permission to use, copy, modify, and/or distribute this software for any purpose"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}},
+}
+
+// returns the SPDX identifier of a license text, or Unknown
+func Classify(text string) string {
+	if m := spdxTag.FindStringSubmatch(text); m != nil {
+		return m[1]
+	}
+
+	normalized := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	for _, r := range rules {
+		if containsAll(normalized, r.phrases) {
+			return r.id
+		}
+	}
+	return Unknown
+}
+
+func containsAll(text string, phrases []string) bool {
+	for _, p := range phrases {
+		if !strings.Contains(text, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// classifies the license files of a module and joins the distinct results
+// the way pkg.go.dev lists them, e.g. "Apache-2.0, MIT"
+func FromFiles(files map[string]string) string {
+	if len(files) == 0 {
+		return None
+	}
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, text := range files {
+		if id := Classify(text); !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	return strings.Join(ids, ", ")
+}
+
+// splits a license list such as "Apache-2.0, MIT" into identifiers
+func Split(licenses string) []string {
+	var ids []string
+	for _, id := range strings.Split(licenses, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// checks every license of a module against the policy and returns why it is
+// not allowed, or "" when it is. An empty list means the license is not known
+// yet and is not judged
+func Check(policy config.LicensePolicy, licenses string) string {
+	for _, id := range Split(licenses) {
+		if matchAny(policy.Deny, id) {
+			return fmt.Sprintf("%s is denied", id)
+		}
+		if len(policy.Allow) > 0 && !matchAny(policy.Allow, id) {
+			return fmt.Sprintf("%s is not allowed", id)
+		}
+	}
+	return ""
+}
+
+// matches case-insensitively; patterns may use * wildcards, as in "GPL-*"
+func matchAny(patterns []string, id string) bool {
+	id = strings.ToLower(id)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == id {
+			return true
+		}
+		if ok, err := path.Match(pattern, id); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
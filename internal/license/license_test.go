@@ -0,0 +1,80 @@
+package license
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MdSadiqMd/gopick/internal/config"
+)
+
+const mitText = `MIT License
+
+Copyright (c) 2024 Example
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction.`
+
+const bsd3Text = `Copyright (c) 2009 The Go Authors. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.`
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"mit", mitText, "MIT"},
+		{"bsd-3", bsd3Text, "BSD-3-Clause"},
+		{"bsd-2", "Redistribution and use in source and binary forms, with or without modification, are permitted", "BSD-2-Clause"},
+		{"apache", "                                 Apache License\n                           Version 2.0, January 2004", "Apache-2.0"},
+		{"lgpl", "GNU LESSER GENERAL PUBLIC LICENSE\n Version 3, 29 June 2007\n\n This version of the GNU General Public License...", "LGPL-3.0"},
+		{"gpl-2", "GNU GENERAL PUBLIC LICENSE\n Version 2, June 1991", "GPL-2.0"},
+		{"mpl", "Mozilla Public License Version 2.0\n==================================", "MPL-2.0"},
+		{"spdx tag", "// SPDX-License-Identifier: EUPL-1.2\n", "EUPL-1.2"},
+		{"unknown", "All rights reserved. Ask us first.", Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Classify(tt.text))
+		})
+	}
+}
+
+func TestFromFiles(t *testing.T) {
+	assert.Equal(t, None, FromFiles(nil))
+	assert.Equal(t, "MIT", FromFiles(map[string]string{"LICENSE": mitText}))
+	assert.Equal(t, "BSD-3-Clause, MIT", FromFiles(map[string]string{
+		"LICENSE":     mitText,
+		"LICENSE-BSD": bsd3Text,
+		"COPYING":     mitText,
+	}))
+}
+
+func TestCheck(t *testing.T) {
+	policy := config.LicensePolicy{
+		Allow: []string{"mit", "BSD-*", "Apache-2.0"},
+		Deny:  []string{"BSD-4-Clause"},
+	}
+
+	assert.Empty(t, Check(policy, "MIT"))
+	assert.Empty(t, Check(policy, "Apache-2.0, BSD-3-Clause"))
+	assert.Empty(t, Check(policy, ""), "an undetected license is not judged")
+	assert.Equal(t, "BSD-4-Clause is denied", Check(policy, "BSD-4-Clause"))
+	assert.Equal(t, "GPL-3.0 is not allowed", Check(policy, "MIT, GPL-3.0"))
+	assert.Equal(t, "None is not allowed", Check(policy, None))
+
+	denyOnly := config.LicensePolicy{Deny: []string{"AGPL-*", "Unknown"}}
+	assert.Empty(t, Check(denyOnly, "GPL-3.0"))
+	assert.Equal(t, "AGPL-3.0 is denied", Check(denyOnly, "AGPL-3.0"))
+	assert.Equal(t, "Unknown is denied", Check(denyOnly, Unknown))
+}
@@ -0,0 +1,68 @@
+package packages
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// returns the requirements of mod's go.mod, direct ones first, then by path
+func (mod Module) Requirements() []Requirement {
+	var reqs []Requirement
+	for _, req := range mod.requires {
+		if !req.Workspace {
+			reqs = append(reqs, req)
+		}
+	}
+
+	sort.Slice(reqs, func(i, j int) bool {
+		if reqs[i].Indirect != reqs[j].Indirect {
+			return !reqs[i].Indirect
+		}
+		return reqs[i].Path < reqs[j].Path
+	})
+
+	return reqs
+}
+
+// drops modulePath from mod with go get @none, which also downgrades what
+// depended on it, then lets go mod tidy clean up go.sum
+func (m *Manager) RemoveDependency(ctx context.Context, mod Module, modulePath string, progress func(string)) error {
+	if progress != nil {
+		progress(fmt.Sprintf("Removing %s...", modulePath))
+	}
+
+	if err := runGo(ctx, mod.Dir, []string{"get", modulePath + "@none"}, progress); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", modulePath, err)
+	}
+	if err := runGo(ctx, mod.Dir, []string{"mod", "tidy"}, progress); err != nil {
+		return fmt.Errorf("go mod tidy failed: %w", err)
+	}
+	m.RefreshCache()
+
+	if progress != nil {
+		progress(fmt.Sprintf("✓ %s removed", modulePath))
+	}
+
+	return nil
+}
+
+// moves the requirement on modulePath to version, which may be lower than
+// the current one or a query such as "latest"
+func (m *Manager) UpdateDependency(ctx context.Context, mod Module, modulePath, version string, progress func(string)) error {
+	target := modulePath + "@" + CanonicalVersion(version)
+	if progress != nil {
+		progress(fmt.Sprintf("Switching to %s...", target))
+	}
+
+	if err := runGo(ctx, mod.Dir, []string{"get", target}, progress); err != nil {
+		return fmt.Errorf("failed to update %s: %w", modulePath, err)
+	}
+	m.RefreshCache()
+
+	if progress != nil {
+		progress(fmt.Sprintf("✓ %s", target))
+	}
+
+	return nil
+}
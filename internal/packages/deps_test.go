@@ -0,0 +1,50 @@
+package packages
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+)
+
+func TestModuleRequirements(t *testing.T) {
+	dir := t.TempDir()
+	goMod := "module example.com/app\n\ngo 1.21\n\nrequire (\n\texample.com/b v1.0.0\n\texample.com/c v1.0.0 // indirect\n\texample.com/a v1.2.0\n)\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644))
+
+	project, err := LoadProject(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Requirement{
+		{Path: "example.com/a", Version: "v1.2.0"},
+		{Path: "example.com/b", Version: "v1.0.0"},
+		{Path: "example.com/c", Version: "v1.0.0", Indirect: true},
+	}, project.Modules[0].Requirements())
+}
+
+func TestUpdateAndRemoveDependency(t *testing.T) {
+	m := newPreviewProject(t)
+	mod := m.Project().Modules[0]
+
+	require.NoError(t, m.UpdateDependency(context.Background(), mod, "example.com/shared", "latest", nil))
+	req, ok := m.Project().Modules[0].Lookup("example.com/shared")
+	require.True(t, ok)
+	assert.Equal(t, "v1.1.0", req.Version)
+
+	require.NoError(t, m.UpdateDependency(context.Background(), mod, "example.com/shared", "1.0.0", nil))
+	req, _ = m.Project().Modules[0].Lookup("example.com/shared")
+	assert.Equal(t, "v1.0.0", req.Version)
+
+	require.NoError(t, m.RemoveDependency(context.Background(), mod, "example.com/shared", nil))
+	_, ok = m.Project().Modules[0].Lookup("example.com/shared")
+	assert.False(t, ok)
+	assert.True(t, NeedsInstall(m.MarkInstalledPackages([]cache.Package{{ImportPath: "example.com/shared"}})[0]))
+
+	err := m.UpdateDependency(context.Background(), mod, "example.com/shared", "v9.9.9", nil)
+	assert.ErrorContains(t, err, "failed to update example.com/shared")
+}
@@ -0,0 +1,293 @@
+package packages
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+type Requirement struct {
+	Path     string `json:"path"`
+	Version  string `json:"version,omitempty"`
+	Indirect bool   `json:"indirect,omitempty"`
+	// set for modules that are part of the workspace itself
+	Workspace bool `json:"workspace,omitempty"`
+}
+
+// Project describes the module (or go.work workspace) gopick was started in
+type Project struct {
+	Dir        string
+	ModulePath string
+	GoModPath  string
+	GoWorkPath string
+	// the main module first, then the other modules used by go.work
+	Modules []Module
+
+	requires map[string]Requirement
+}
+
+// Module is a single module of the project that packages can be added to
+type Module struct {
+	Path      string
+	Dir       string
+	GoModPath string
+	// packages listed by tool directives
+	Tools []string
+
+	requires map[string]Requirement
+}
+
+// finds the go.mod enclosing dir (and go.work, if any) and parses them
+func LoadProject(dir string) (*Project, error) {
+	goModPath := findUp(dir, "go.mod")
+	if goModPath == "" {
+		return nil, fmt.Errorf("no go.mod found in %s or any parent directory", dir)
+	}
+
+	p := &Project{
+		Dir:       filepath.Dir(goModPath),
+		GoModPath: goModPath,
+		requires:  make(map[string]Requirement),
+	}
+
+	mf, err := parseGoMod(goModPath)
+	if err != nil {
+		return nil, err
+	}
+	p.addRequirements(mf)
+	if mf.Module != nil {
+		p.ModulePath = mf.Module.Mod.Path
+		p.requires[p.ModulePath] = Requirement{Path: p.ModulePath, Workspace: true}
+	}
+	p.Modules = append(p.Modules, newModule(p.Dir, goModPath, mf))
+
+	goWorkPath := findGoWork(p.Dir)
+	if goWorkPath == "" {
+		return p, nil
+	}
+
+	if err := p.loadWorkspace(goWorkPath); err != nil {
+		return nil, err
+	}
+	p.GoWorkPath = goWorkPath
+
+	return p, nil
+}
+
+// returns the requirement that provides importPath, if any
+func (p *Project) Lookup(importPath string) (Requirement, bool) {
+	return lookup(p.requires, importPath)
+}
+
+// returns the requirement of this module alone that provides importPath
+func (mod Module) Lookup(importPath string) (Requirement, bool) {
+	return lookup(mod.requires, importPath)
+}
+
+// finds a module of the project by module path or by directory, which may be
+// relative to the current directory
+func (p *Project) Module(pathOrDir string) (Module, bool) {
+	dir, _ := filepath.Abs(pathOrDir)
+	for _, mod := range p.Modules {
+		if mod.Path == pathOrDir || mod.Dir == dir {
+			return mod, true
+		}
+	}
+	return Module{}, false
+}
+
+// longest-prefix match of importPath against the required module paths
+func lookup(requires map[string]Requirement, importPath string) (Requirement, bool) {
+	var best Requirement
+	found := false
+
+	for path, req := range requires {
+		if importPath != path && !strings.HasPrefix(importPath, path+"/") {
+			continue
+		}
+		if !found || len(path) > len(best.Path) {
+			best = req
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// returns all requirements of the project, in no particular order
+func (p *Project) Requirements() []Requirement {
+	reqs := make([]Requirement, 0, len(p.requires))
+	for _, req := range p.requires {
+		reqs = append(reqs, req)
+	}
+	return reqs
+}
+
+func (p *Project) addRequirements(mf *modfile.File) {
+	for _, r := range mf.Require {
+		// a direct requirement anywhere in the workspace wins over an indirect one
+		if existing, ok := p.requires[r.Mod.Path]; ok && !existing.Indirect {
+			continue
+		}
+		p.requires[r.Mod.Path] = Requirement{
+			Path:     r.Mod.Path,
+			Version:  r.Mod.Version,
+			Indirect: r.Indirect,
+		}
+	}
+}
+
+func (p *Project) loadWorkspace(goWorkPath string) error {
+	data, err := os.ReadFile(goWorkPath)
+	if err != nil {
+		return fmt.Errorf("failed to read go.work: %w", err)
+	}
+
+	wf, err := modfile.ParseWork(goWorkPath, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to parse go.work: %w", err)
+	}
+
+	workDir := filepath.Dir(goWorkPath)
+	for _, use := range wf.Use {
+		modDir := use.Path
+		if !filepath.IsAbs(modDir) {
+			modDir = filepath.Join(workDir, modDir)
+		}
+
+		mf, err := parseGoMod(filepath.Join(modDir, "go.mod"))
+		if err != nil {
+			// go.work may list modules that are not checked out; skip them
+			continue
+		}
+
+		p.addRequirements(mf)
+		if mf.Module != nil {
+			p.requires[mf.Module.Mod.Path] = Requirement{
+				Path:      mf.Module.Mod.Path,
+				Workspace: true,
+			}
+		}
+
+		if _, ok := p.Module(modDir); !ok {
+			p.Modules = append(p.Modules, newModule(modDir, filepath.Join(modDir, "go.mod"), mf))
+		}
+	}
+
+	return nil
+}
+
+func newModule(dir, goModPath string, mf *modfile.File) Module {
+	mod := Module{
+		Dir:       dir,
+		GoModPath: goModPath,
+		requires:  make(map[string]Requirement),
+	}
+
+	for _, r := range mf.Require {
+		mod.requires[r.Mod.Path] = Requirement{
+			Path:     r.Mod.Path,
+			Version:  r.Mod.Version,
+			Indirect: r.Indirect,
+		}
+	}
+	if mf.Module != nil {
+		mod.Path = mf.Module.Mod.Path
+		mod.requires[mod.Path] = Requirement{Path: mod.Path, Workspace: true}
+	}
+	forEachTool(mf.Syntax, func(path string, line *modfile.Line) {
+		mod.Tools = append(mod.Tools, path)
+	})
+
+	return mod
+}
+
+// reports whether importPath is listed by a tool directive of mod
+func (mod Module) HasTool(importPath string) bool {
+	for _, tool := range mod.Tools {
+		if tool == importPath {
+			return true
+		}
+	}
+	return false
+}
+
+// calls fn for every tool directive of the file. The directives are read
+// from the syntax tree, which has them whether or not modfile knows the
+// tool directive; releases that don't leave it out of the parsed File
+func forEachTool(syntax *modfile.FileSyntax, fn func(path string, line *modfile.Line)) {
+	for _, stmt := range syntax.Stmt {
+		switch stmt := stmt.(type) {
+		case *modfile.Line:
+			if len(stmt.Token) == 2 && stmt.Token[0] == "tool" {
+				fn(unquote(stmt.Token[1]), stmt)
+			}
+		case *modfile.LineBlock:
+			if len(stmt.Token) == 1 && stmt.Token[0] == "tool" {
+				for _, line := range stmt.Line {
+					if len(line.Token) == 1 {
+						fn(unquote(line.Token[0]), line)
+					}
+				}
+			}
+		}
+	}
+}
+
+func unquote(token string) string {
+	if s, err := strconv.Unquote(token); err == nil {
+		return s
+	}
+	return token
+}
+
+func parseGoMod(path string) (*modfile.File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.mod: %w", err)
+	}
+
+	mf, err := modfile.ParseLax(path, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+
+	return mf, nil
+}
+
+// honors GOWORK the same way the go command does
+func findGoWork(dir string) string {
+	switch gowork := os.Getenv("GOWORK"); gowork {
+	case "off":
+		return ""
+	case "":
+		return findUp(dir, "go.work")
+	default:
+		return gowork
+	}
+}
+
+// walks up from dir looking for name, returning its path or ""
+func findUp(dir, name string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,185 @@
+package packages
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGoMod = `module example.com/app
+
+go 1.21
+
+require (
+	github.com/spf13/cobra v1.8.0
+	golang.org/x/text v0.13.0 // indirect
+)
+`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestLoadProject(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+
+	tempDir := t.TempDir()
+	writeFile(t, filepath.Join(tempDir, "go.mod"), testGoMod)
+
+	// should be found from a nested directory
+	subDir := filepath.Join(tempDir, "internal", "pkg")
+	require.NoError(t, os.MkdirAll(subDir, 0755))
+
+	p, err := LoadProject(subDir)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/app", p.ModulePath)
+	assert.Equal(t, filepath.Join(tempDir, "go.mod"), p.GoModPath)
+	assert.Empty(t, p.GoWorkPath)
+
+	req, ok := p.Lookup("github.com/spf13/cobra")
+	require.True(t, ok)
+	assert.Equal(t, "v1.8.0", req.Version)
+	assert.False(t, req.Indirect)
+
+	req, ok = p.Lookup("golang.org/x/text/language")
+	require.True(t, ok)
+	assert.Equal(t, "golang.org/x/text", req.Path)
+	assert.True(t, req.Indirect)
+
+	_, ok = p.Lookup("github.com/spf13/cobra-cli")
+	assert.False(t, ok)
+
+	req, ok = p.Lookup("example.com/app/internal/pkg")
+	require.True(t, ok)
+	assert.True(t, req.Workspace)
+}
+
+func TestLoadProjectNoGoMod(t *testing.T) {
+	_, err := LoadProject(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestLoadProjectWorkspace(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("GOWORK", "")
+
+	writeFile(t, filepath.Join(tempDir, "go.work"), "go 1.21\n\nuse (\n\t./app\n\t./lib\n\t./missing\n)\n")
+	writeFile(t, filepath.Join(tempDir, "app", "go.mod"), testGoMod)
+	writeFile(t, filepath.Join(tempDir, "lib", "go.mod"), `module example.com/lib
+
+go 1.21
+
+require golang.org/x/text v0.14.0
+`)
+
+	p, err := LoadProject(filepath.Join(tempDir, "app"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tempDir, "go.work"), p.GoWorkPath)
+
+	req, ok := p.Lookup("example.com/lib/util")
+	require.True(t, ok)
+	assert.True(t, req.Workspace)
+
+	// direct requirement in lib beats the indirect one in app
+	req, ok = p.Lookup("golang.org/x/text")
+	require.True(t, ok)
+	assert.False(t, req.Indirect)
+	assert.Equal(t, "v0.14.0", req.Version)
+
+	// the main module comes first and the missing one is skipped
+	require.Len(t, p.Modules, 2)
+	assert.Equal(t, "example.com/app", p.Modules[0].Path)
+	assert.Equal(t, filepath.Join(tempDir, "app"), p.Modules[0].Dir)
+	assert.Equal(t, "example.com/lib", p.Modules[1].Path)
+
+	// each module keeps its own requirements
+	req, ok = p.Modules[0].Lookup("golang.org/x/text")
+	require.True(t, ok)
+	assert.True(t, req.Indirect)
+	_, ok = p.Modules[1].Lookup("github.com/spf13/cobra")
+	assert.False(t, ok)
+
+	mod, ok := p.Module("example.com/lib")
+	require.True(t, ok)
+	assert.Equal(t, filepath.Join(tempDir, "lib"), mod.Dir)
+	mod, ok = p.Module(filepath.Join(tempDir, "lib"))
+	require.True(t, ok)
+	assert.Equal(t, "example.com/lib", mod.Path)
+	_, ok = p.Module("example.com/missing")
+	assert.False(t, ok)
+}
+
+func TestGetInstallCommandIn(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("GOWORK", "")
+
+	writeFile(t, filepath.Join(tempDir, "go.work"), "go 1.21\n\nuse (\n\t./app\n\t./lib\n)\n")
+	writeFile(t, filepath.Join(tempDir, "app", "go.mod"), testGoMod)
+	writeFile(t, filepath.Join(tempDir, "lib", "go.mod"), "module example.com/lib\n\ngo 1.21\n")
+
+	p, err := LoadProject(filepath.Join(tempDir, "app"))
+	require.NoError(t, err)
+
+	m := New(filepath.Join(tempDir, "modcache"))
+	m.SetProject(p)
+
+	pkgs := []cache.Package{
+		{Name: "cobra", ImportPath: "github.com/spf13/cobra", IsInstalled: true},
+		{Name: "viper", ImportPath: "github.com/spf13/viper"},
+	}
+	app, lib := p.Modules[0], p.Modules[1]
+
+	// from the app module no cd is needed, and app already has cobra
+	assert.Equal(t, "go get github.com/spf13/viper",
+		m.GetInstallCommandIn(pkgs, []Module{app}, filepath.Join(tempDir, "app")))
+
+	assert.Equal(t, "cd ../lib && go get github.com/spf13/cobra github.com/spf13/viper",
+		m.GetInstallCommandIn(pkgs, []Module{lib}, filepath.Join(tempDir, "app")))
+
+	assert.Equal(t, "(cd app && go get github.com/spf13/viper) && (cd lib && go get github.com/spf13/cobra github.com/spf13/viper)",
+		m.GetInstallCommandIn(pkgs, []Module{app, lib}, tempDir))
+
+	// without modules the workspace-wide status decides
+	assert.Equal(t, "go get github.com/spf13/viper", m.GetInstallCommandIn(pkgs, nil, tempDir))
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, "../lib", shellQuote("../lib"))
+	assert.Equal(t, "'my dir'", shellQuote("my dir"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+}
+
+func TestMarkInstalledPackagesWithProject(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+
+	tempDir := t.TempDir()
+	writeFile(t, filepath.Join(tempDir, "go.mod"), testGoMod)
+
+	p, err := LoadProject(tempDir)
+	require.NoError(t, err)
+
+	m := New(filepath.Join(tempDir, "modcache"))
+	m.SetProject(p)
+
+	marked := m.MarkInstalledPackages([]cache.Package{
+		{Name: "cobra", ImportPath: "github.com/spf13/cobra"},
+		{Name: "language", ImportPath: "golang.org/x/text/language"},
+		{Name: "viper", ImportPath: "github.com/spf13/viper"},
+	})
+
+	assert.True(t, marked[0].InModule)
+	assert.True(t, marked[0].IsInstalled)
+	assert.Equal(t, "v1.8.0", marked[0].RequiredVersion)
+
+	assert.True(t, marked[1].InModule)
+	assert.True(t, marked[1].Indirect)
+
+	assert.False(t, marked[2].InModule)
+	assert.False(t, marked[2].IsInstalled)
+}
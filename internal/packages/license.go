@@ -0,0 +1,60 @@
+package packages
+
+import (
+	"fmt"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+	"github.com/MdSadiqMd/gopick/internal/license"
+	"github.com/MdSadiqMd/gopick/internal/proxy"
+)
+
+// the license of the Go distribution, standard library included
+const stdlibLicense = "BSD-3-Clause"
+
+// returns the license of the module pkg would be installed from. A license
+// scraped from pkg.go.dev is used as is; otherwise the license files of the
+// module zip are classified. Results are remembered per module version
+func (m *Manager) DetectLicense(pkg cache.Package) (string, error) {
+	if pkg.License != "" {
+		return pkg.License, nil
+	}
+	if pkg.Stdlib {
+		return stdlibLicense, nil
+	}
+
+	m.mu.RLock()
+	resolver := m.resolver
+	m.mu.RUnlock()
+	if resolver == nil || resolver.proxy == nil {
+		return "", proxy.ErrDisabled
+	}
+
+	mv := m.InstallModule(pkg)
+	if mv.Version == "" {
+		latest, err := resolver.proxy.Latest(mv.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", mv.Path, err)
+		}
+		mv.Version = latest.Version
+	}
+
+	key := mv.String()
+	m.mu.RLock()
+	known, ok := m.licenses[key]
+	m.mu.RUnlock()
+	if ok {
+		return known, nil
+	}
+
+	files, err := resolver.proxy.LicenseFiles(mv.Path, mv.Version)
+	if err != nil {
+		return "", err
+	}
+
+	known = license.FromFiles(files)
+	m.mu.Lock()
+	m.licenses[key] = known
+	m.mu.Unlock()
+
+	return known, nil
+}
@@ -0,0 +1,61 @@
+package packages
+
+import (
+	"archive/zip"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+	"github.com/MdSadiqMd/gopick/internal/license"
+	"github.com/MdSadiqMd/gopick/internal/proxy"
+)
+
+func TestDetectLicense(t *testing.T) {
+	var zips int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/lib/@latest":
+			w.Write([]byte(`{"Version":"v1.1.0"}`))
+		case "/example.com/lib/@v/v1.1.0.zip", "/example.com/bare/@v/v1.0.0.zip":
+			atomic.AddInt32(&zips, 1)
+			zw := zip.NewWriter(w)
+			f, _ := zw.Create("example.com/lib@v1.1.0/LICENSE")
+			f.Write([]byte("Permission is hereby granted, free of charge, to any person"))
+			f, _ = zw.Create("example.com/bare@v1.0.0/bare.go")
+			f.Write([]byte("package bare\n"))
+			zw.Close()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	m := New(t.TempDir())
+	m.SetResolver(NewResolver(proxy.New(proxy.Settings{GoProxy: server.URL})))
+
+	lib := cache.Package{ImportPath: "example.com/lib/sub", ModulePath: "example.com/lib"}
+	id, err := m.DetectLicense(lib)
+	require.NoError(t, err)
+	assert.Equal(t, "MIT", id)
+
+	// classifications are remembered per module version
+	id, err = m.DetectLicense(lib)
+	require.NoError(t, err)
+	assert.Equal(t, "MIT", id)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&zips))
+
+	id, err = m.DetectLicense(cache.Package{ImportPath: "example.com/bare", Version: "1.0.0"})
+	require.NoError(t, err)
+	assert.Equal(t, license.None, id)
+
+	// a scraped license needs no download
+	id, err = m.DetectLicense(cache.Package{ImportPath: "example.com/other", License: "Apache-2.0"})
+	require.NoError(t, err)
+	assert.Equal(t, "Apache-2.0", id)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&zips))
+}
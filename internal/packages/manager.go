@@ -2,27 +2,125 @@ package packages
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
 
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+
 	"github.com/MdSadiqMd/gopick/internal/cache"
 )
 
 type Manager struct {
 	goModCachePath string
 	installedCache map[string]bool
-	mu             sync.RWMutex
+	project        *Project
+	resolver       *Resolver
+	// import path -> package main, as detected through the proxy
+	commands map[string]bool
+	// module@version -> license classified from its zip
+	licenses        map[string]string
+	goBin           string
+	goVersion       string
+	goVersionLoaded bool
+	// import paths of the standard library, nil until loaded
+	stdlib map[string]bool
+	mu     sync.RWMutex
 }
 
 func New(goModCachePath string) *Manager {
 	return &Manager{
 		goModCachePath: goModCachePath,
 		installedCache: make(map[string]bool),
+		commands:       make(map[string]bool),
+		licenses:       make(map[string]string),
+	}
+}
+
+// makes install status follow the given project's go.mod instead of the module cache
+func (m *Manager) SetProject(p *Project) {
+	m.mu.Lock()
+	m.project = p
+	m.mu.Unlock()
+}
+
+// enables proxy-backed module resolution for nested packages
+func (m *Manager) SetResolver(r *Resolver) {
+	m.mu.Lock()
+	m.resolver = r
+	m.mu.Unlock()
+}
+
+// returns the module that provides importPath, preferring what go.mod already
+// says. Without a matching requirement it uses what ResolveModules learned
+// from the proxy, or else the path heuristic, so it never waits on the network
+func (m *Manager) ModulePath(importPath string) string {
+	if project := m.Project(); project != nil {
+		if req, ok := project.Lookup(importPath); ok {
+			return req.Path
+		}
 	}
+
+	m.mu.RLock()
+	resolver := m.resolver
+	m.mu.RUnlock()
+
+	return resolver.Known(importPath)
+}
+
+// how many import paths ResolveModules looks up at once
+const resolveWorkers = 8
+
+// asks the proxy which module provides each of importPaths that go.mod does
+// not already answer for, so later ModulePath calls know nested modules. It
+// waits on the network and belongs off the UI goroutine
+func (m *Manager) ResolveModules(importPaths []string) {
+	m.mu.RLock()
+	resolver := m.resolver
+	m.mu.RUnlock()
+	if resolver == nil {
+		return
+	}
+	project := m.Project()
+
+	sem := make(chan struct{}, resolveWorkers)
+	var wg sync.WaitGroup
+	for _, importPath := range importPaths {
+		if project != nil {
+			if _, ok := project.Lookup(importPath); ok {
+				continue
+			}
+		}
+
+		importPath := importPath
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			before := resolver.Known(importPath)
+			if resolver.Resolve(importPath) != before {
+				// the install status was checked against the wrong module
+				m.mu.Lock()
+				delete(m.installedCache, importPath)
+				m.mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (m *Manager) Project() *Project {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.project
 }
 
 func (m *Manager) IsInstalled(importPath string) bool {
@@ -42,32 +140,33 @@ func (m *Manager) IsInstalled(importPath string) bool {
 	return installed
 }
 
-// checks if a package exists in go mod cache
+// checks if the module providing a package exists in go mod cache
 func (m *Manager) checkInstalled(importPath string) bool {
-	// github.com/user/repo -> github.com/user/repo@version
-	parts := strings.Split(importPath, "/")
-	if len(parts) < 2 {
+	// github.com/user/repo/sub -> github.com/user/repo@version
+	modulePath := m.ModulePath(importPath)
+	if !strings.Contains(modulePath, "/") {
 		return false
 	}
 
-	searchPath := filepath.Join(m.goModCachePath, parts[0])
-	if len(parts) > 1 {
-		searchPath = filepath.Join(searchPath, parts[1])
+	escaped, err := module.EscapePath(modulePath)
+	if err != nil {
+		return false
 	}
 
+	searchPath := filepath.Join(m.goModCachePath, filepath.FromSlash(path.Dir(escaped)))
 	entries, err := os.ReadDir(searchPath)
 	if err != nil {
 		return false
 	}
 
-	packageName := parts[len(parts)-1]
+	prefix := path.Base(escaped) + "@"
 	for _, entry := range entries {
-		if entry.IsDir() && strings.HasPrefix(entry.Name(), packageName+"@") {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), prefix) {
 			return true
 		}
 	}
 
-	cmd := exec.Command("go", "list", "-m", importPath)
+	cmd := exec.Command("go", "list", "-m", modulePath)
 	output, err := cmd.Output()
 	if err == nil && strings.TrimSpace(string(output)) != "" {
 		return true
@@ -79,9 +178,40 @@ func (m *Manager) checkInstalled(importPath string) bool {
 func (m *Manager) MarkInstalledPackages(packages []cache.Package) []cache.Package {
 	result := make([]cache.Package, len(packages))
 
+	project := m.Project()
+
 	for i, pkg := range packages {
 		result[i] = pkg
-		result[i].IsInstalled = m.IsInstalled(pkg.ImportPath)
+		if m.IsStdlib(pkg.ImportPath) {
+			// ships with Go, so there is no module to resolve or install
+			result[i].Stdlib = true
+			result[i].ModulePath = "std"
+			continue
+		}
+		result[i].Stdlib = false
+
+		result[i].ModulePath = m.ModulePath(pkg.ImportPath)
+		result[i].InModCache = m.IsInstalled(pkg.ImportPath)
+		result[i].IsInstalled = result[i].InModCache
+		if m.isCommand(pkg) {
+			result[i].Command = true
+			result[i].InGoBin = m.IsBinaryInstalled(pkg.ImportPath)
+		}
+
+		if project == nil {
+			continue
+		}
+
+		result[i].Tool = project.Modules[0].HasTool(pkg.ImportPath)
+		if result[i].Tool {
+			result[i].Command = true
+		}
+
+		req, ok := project.Lookup(pkg.ImportPath)
+		result[i].InModule = ok
+		result[i].RequiredVersion = req.Version
+		result[i].Indirect = req.Indirect
+		result[i].IsInstalled = ok
 	}
 
 	return result
@@ -91,12 +221,8 @@ func (m *Manager) GetInstallCommand(packages []cache.Package) string {
 	var pkgs []string
 
 	for _, pkg := range packages {
-		if !pkg.IsInstalled {
-			if pkg.Version != "" {
-				pkgs = append(pkgs, fmt.Sprintf("%s@%s", pkg.ImportPath, pkg.Version))
-			} else {
-				pkgs = append(pkgs, pkg.ImportPath)
-			}
+		if NeedsInstall(pkg) {
+			pkgs = append(pkgs, m.InstallTarget(pkg))
 		}
 	}
 
@@ -107,16 +233,174 @@ func (m *Manager) GetInstallCommand(packages []cache.Package) string {
 	return fmt.Sprintf("go get %s", strings.Join(pkgs, " "))
 }
 
-func (m *Manager) InstallPackage(importPath string, progress func(string)) error {
+// returns the command that adds packages to each of modules by running go get
+// from the module directories, shown relative to cwd. Without modules it is
+// the same as GetInstallCommand
+func (m *Manager) GetInstallCommandIn(packages []cache.Package, modules []Module, cwd string) string {
+	if len(modules) == 0 {
+		return m.GetInstallCommand(packages)
+	}
+
+	cwdModuleDir := filepath.Dir(findUp(cwd, "go.mod"))
+
+	var commands []string
+	for _, mod := range modules {
+		var targets []string
+		for _, pkg := range packages {
+			if mod.NeedsInstall(pkg) {
+				targets = append(targets, m.InstallTarget(pkg))
+			}
+		}
+		if len(targets) == 0 {
+			continue
+		}
+
+		command := "go get " + strings.Join(targets, " ")
+		if mod.Dir != cwdModuleDir {
+			dir, err := filepath.Rel(cwd, mod.Dir)
+			if err != nil {
+				dir = mod.Dir
+			}
+			command = fmt.Sprintf("cd %s && %s", shellQuote(dir), command)
+		}
+		commands = append(commands, command)
+	}
+
+	// subshells keep each cd from affecting the next module
+	if len(commands) > 1 {
+		for i, command := range commands {
+			if strings.HasPrefix(command, "cd ") {
+				commands[i] = "(" + command + ")"
+			}
+		}
+	}
+
+	return strings.Join(commands, " && ")
+}
+
+// quotes s for POSIX shells when it contains anything but safe characters
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./+@") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// reports whether pkg should be passed to go get; installed packages are
+// skipped unless a different version was pinned
+func NeedsInstall(pkg cache.Package) bool {
+	if pkg.Stdlib {
+		return false
+	}
+	if !pkg.IsInstalled {
+		return true
+	}
+	return pkg.Pinned && CanonicalVersion(pkg.Version) != pkg.RequiredVersion
+}
+
+// reports whether pkg has to be added to mod. Unlike NeedsInstall, only the
+// requirements of this one module count, not those of the whole workspace
+func (mod Module) NeedsInstall(pkg cache.Package) bool {
+	if pkg.Stdlib {
+		return false
+	}
+	req, ok := mod.Lookup(pkg.ImportPath)
+	if !ok {
+		return true
+	}
+	return pkg.Pinned && CanonicalVersion(pkg.Version) != req.Version
+}
+
+// returns the go get argument for pkg. A pinned version belongs to the
+// module, so it is applied to the module path rather than the package
+func (m *Manager) InstallTarget(pkg cache.Package) string {
+	if pkg.Version == "" {
+		return pkg.ImportPath
+	}
+
+	modulePath := pkg.ModulePath
+	if modulePath == "" {
+		modulePath = m.ModulePath(pkg.ImportPath)
+	}
+
+	return fmt.Sprintf("%s@%s", modulePath, CanonicalVersion(pkg.Version))
+}
+
+// StdlibModule is the module path the Go vulnerability database files
+// standard library packages under
+const StdlibModule = "stdlib"
+
+// returns the module and version that installing pkg adds to go.mod. The
+// version is empty when only go get can tell, e.g. for a plain import path.
+// Standard library packages come with the toolchain, so they are the stdlib
+// module at the version of the go command
+func (m *Manager) InstallModule(pkg cache.Package) module.Version {
+	if pkg.Stdlib {
+		return module.Version{Path: StdlibModule, Version: m.GoVersion()}
+	}
+
+	modulePath := pkg.ModulePath
+	if modulePath == "" {
+		modulePath = m.ModulePath(pkg.ImportPath)
+	}
+
+	version := CanonicalVersion(pkg.Version)
+	if !pkg.Pinned && pkg.InModule {
+		// installed packages are skipped, so the requirement stays as it is
+		version = pkg.RequiredVersion
+	}
+	if !semver.IsValid(version) {
+		version = ""
+	}
+
+	return module.Version{Path: modulePath, Version: version}
+}
+
+// adds the "v" prefix that pkg.go.dev snippets drop
+func CanonicalVersion(version string) string {
+	switch {
+	case version == "", version == "latest", version == "upgrade", version == "patch", version == "none":
+		return version
+	case strings.HasPrefix(version, "v"):
+		return version
+	default:
+		return "v" + version
+	}
+}
+
+// runs go get for target, which is an import path optionally followed by @version
+func (m *Manager) InstallPackage(ctx context.Context, target string, progress func(string)) error {
+	return m.InstallPackageIn(ctx, "", target, progress)
+}
+
+// runs go get for target from dir, adding it to the module in dir
+func (m *Manager) InstallPackageIn(ctx context.Context, dir, target string, progress func(string)) error {
+	importPath, _, _ := strings.Cut(target, "@")
+
 	m.mu.Lock()
 	delete(m.installedCache, importPath)
 	m.mu.Unlock()
 
 	if progress != nil {
-		progress(fmt.Sprintf("Installing %s...", importPath))
+		progress(fmt.Sprintf("Installing %s...", target))
+	}
+
+	if err := runGo(ctx, dir, []string{"get", target}, progress); err != nil {
+		return fmt.Errorf("installation failed: %w", err)
 	}
 
-	cmd := exec.Command("go", "get", importPath)
+	if progress != nil {
+		progress(fmt.Sprintf("✓ %s installed successfully", target))
+	}
+
+	return nil
+}
+
+// runs the go command in dir, passing every output line to progress. The
+// error carries the stderr output; cancelling ctx kills the command
+func runGo(ctx context.Context, dir string, args []string, progress func(string)) error {
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -129,11 +413,16 @@ func (m *Manager) InstallPackage(importPath string, progress func(string)) error
 	}
 
 	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start installation: %w", err)
+		return fmt.Errorf("failed to start go %s: %w", args[0], err)
 	}
 
+	// both pipes must be drained before Wait closes them
+	var wg sync.WaitGroup
+	wg.Add(2)
+
 	scanner := bufio.NewScanner(stdout)
 	go func() {
+		defer wg.Done()
 		for scanner.Scan() {
 			if progress != nil {
 				progress(scanner.Text())
@@ -144,48 +433,103 @@ func (m *Manager) InstallPackage(importPath string, progress func(string)) error
 	errScanner := bufio.NewScanner(stderr)
 	var errOutput strings.Builder
 	go func() {
+		defer wg.Done()
 		for errScanner.Scan() {
 			line := errScanner.Text()
 			errOutput.WriteString(line + "\n")
-			if progress != nil && strings.Contains(line, "downloading") {
+			if progress != nil {
 				progress(line)
 			}
 		}
 	}()
 
+	wg.Wait()
 	if err := cmd.Wait(); err != nil {
-		return fmt.Errorf("installation failed: %w\n%s", err, errOutput.String())
+		return fmt.Errorf("%w\n%s", err, errOutput.String())
 	}
 
-	if progress != nil {
-		progress(fmt.Sprintf("✓ %s installed successfully", importPath))
+	return nil
+}
+
+func (m *Manager) InstallPackages(ctx context.Context, packages []cache.Package, progress func(string, float64)) error {
+	return m.installPackages(ctx, "", NeedsInstall, packages, progress)
+}
+
+// like InstallPackages, adding the packages to the given module of the workspace
+func (m *Manager) InstallPackagesIn(ctx context.Context, mod Module, packages []cache.Package, progress func(string, float64)) error {
+	return m.installPackages(ctx, mod.Dir, mod.NeedsInstall, packages, progress)
+}
+
+// InstallFailure is a package that go get failed to install
+type InstallFailure struct {
+	Package cache.Package
+	Err     error
+}
+
+// InstallError is returned by InstallPackages when some of the packages
+// failed; the others were installed
+type InstallError []InstallFailure
+
+func (e InstallError) Error() string {
+	lines := []string{fmt.Sprintf("%d package(s) failed to install", len(e))}
+	for _, f := range e {
+		lines = append(lines, fmt.Sprintf("%s: %v", f.Package.ImportPath, f.Err))
 	}
+	return strings.Join(lines, "\n")
+}
 
-	return nil
+// reports whether importPath is among the failures
+func (e InstallError) Failed(importPath string) bool {
+	for _, f := range e {
+		if f.Package.ImportPath == importPath {
+			return true
+		}
+	}
+	return false
 }
 
-func (m *Manager) InstallPackages(packages []cache.Package, progress func(string, float64)) error {
+// installs the packages one by one, going on past failures so one bad
+// package doesn't hold back the rest. Cancelling ctx stops at the package
+// being installed
+func (m *Manager) installPackages(ctx context.Context, dir string, needsInstall func(cache.Package) bool, packages []cache.Package, progress func(string, float64)) error {
 	total := len(packages)
+	var failures InstallError
 
 	for i, pkg := range packages {
-		if pkg.IsInstalled {
+		if !needsInstall(pkg) {
 			if progress != nil {
-				progress(fmt.Sprintf("✓ %s already installed", pkg.ImportPath), float64(i+1)/float64(total)*100)
+				status := "already installed"
+				if pkg.Stdlib {
+					status = "is in the standard library"
+				}
+				progress(fmt.Sprintf("✓ %s %s", pkg.ImportPath, status), float64(i+1)/float64(total)*100)
 			}
 			continue
 		}
 
-		err := m.InstallPackage(pkg.ImportPath, func(msg string) {
+		err := m.InstallPackageIn(ctx, dir, m.InstallTarget(pkg), func(msg string) {
 			if progress != nil {
 				progress(msg, float64(i+1)/float64(total)*100)
 			}
 		})
-
+		if ctx.Err() != nil {
+			m.RefreshCache()
+			return ctx.Err()
+		}
 		if err != nil {
-			return fmt.Errorf("failed to install %s: %w", pkg.ImportPath, err)
+			failures = append(failures, InstallFailure{Package: pkg, Err: err})
+			if progress != nil {
+				progress(fmt.Sprintf("✗ %s failed", pkg.ImportPath), float64(i+1)/float64(total)*100)
+			}
 		}
 	}
 
+	m.RefreshCache()
+
+	if len(failures) > 0 {
+		return failures
+	}
+
 	if progress != nil {
 		progress("All packages installed successfully!", 100)
 	}
@@ -197,6 +541,20 @@ func (m *Manager) RefreshCache() {
 	m.mu.Lock()
 	m.installedCache = make(map[string]bool)
 	m.mu.Unlock()
+
+	m.reloadProject()
+}
+
+// re-reads go.mod/go.work after they may have been changed by go get
+func (m *Manager) reloadProject() {
+	project := m.Project()
+	if project == nil {
+		return
+	}
+
+	if reloaded, err := LoadProject(project.Dir); err == nil {
+		m.SetProject(reloaded)
+	}
 }
 
 func (m *Manager) GetGoEnv(key string) (string, error) {
@@ -1,6 +1,7 @@
 package packages
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -95,6 +96,22 @@ func TestGetInstallCommand(t *testing.T) {
 			},
 			expected: "go get github.com/spf13/cobra github.com/gin-gonic/gin@v1.8.1",
 		},
+		{
+			name: "version is pinned on the owning module",
+			packages: []cache.Package{
+				{
+					Name:       "language",
+					ImportPath: "golang.org/x/text/language",
+					Version:    "0.13.0",
+				},
+				{
+					Name:       "middleware",
+					ImportPath: "github.com/go-chi/chi/v5/middleware",
+					Version:    "v5.0.10",
+				},
+			},
+			expected: "go get golang.org/x/text@v0.13.0 github.com/go-chi/chi/v5@v5.0.10",
+		},
 		{
 			name: "skip installed packages",
 			packages: []cache.Package{
@@ -111,6 +128,28 @@ func TestGetInstallCommand(t *testing.T) {
 			},
 			expected: "go get github.com/spf13/cobra",
 		},
+		{
+			name: "pinned version differs from the required one",
+			packages: []cache.Package{
+				{
+					Name:            "viper",
+					ImportPath:      "github.com/spf13/viper",
+					Version:         "v1.16.0",
+					Pinned:          true,
+					IsInstalled:     true,
+					RequiredVersion: "v1.18.2",
+				},
+				{
+					Name:            "cobra",
+					ImportPath:      "github.com/spf13/cobra",
+					Version:         "v1.8.0",
+					Pinned:          true,
+					IsInstalled:     true,
+					RequiredVersion: "v1.8.0",
+				},
+			},
+			expected: "go get github.com/spf13/viper@v1.16.0",
+		},
 		{
 			name: "all installed",
 			packages: []cache.Package{
@@ -132,6 +171,23 @@ func TestGetInstallCommand(t *testing.T) {
 	}
 }
 
+func TestIsInstalledNestedPackage(t *testing.T) {
+	tempDir := t.TempDir()
+	m := New(tempDir)
+
+	pkgPath := filepath.Join(tempDir, "github.com", "go-chi", "chi", "v5@v5.0.10")
+	require.NoError(t, os.MkdirAll(pkgPath, 0755))
+
+	assert.True(t, m.IsInstalled("github.com/go-chi/chi/v5/middleware"))
+}
+
+func TestCanonicalVersion(t *testing.T) {
+	assert.Equal(t, "v1.2.3", CanonicalVersion("1.2.3"))
+	assert.Equal(t, "v1.2.3", CanonicalVersion("v1.2.3"))
+	assert.Equal(t, "latest", CanonicalVersion("latest"))
+	assert.Equal(t, "", CanonicalVersion(""))
+}
+
 func TestRefreshCache(t *testing.T) {
 	tempDir := t.TempDir()
 	m := New(tempDir)
@@ -147,10 +203,29 @@ func TestRefreshCache(t *testing.T) {
 func TestInstallPackageError(t *testing.T) {
 	m := &Manager{}
 
-	err := m.InstallPackage("github.com/nonexistent/package/that/does/not/exist", nil)
+	err := m.InstallPackage(context.Background(), "github.com/nonexistent/package/that/does/not/exist", nil)
 	assert.Error(t, err)
 }
 
+func TestInstallPackageStreamsStderr(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	defer os.Chdir(wd)
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOWORK", "off")
+
+	var lines []string
+	m := &Manager{}
+	err = m.InstallPackage(context.Background(), "github.com/test/pkg", func(line string) {
+		lines = append(lines, line)
+	})
+	require.Error(t, err)
+
+	// outside a module go get fails before touching the network
+	assert.Contains(t, strings.Join(lines, "\n"), "go.mod file not found")
+}
+
 func TestInstallPackages(t *testing.T) {
 	m := &Manager{}
 
@@ -163,7 +238,7 @@ func TestInstallPackages(t *testing.T) {
 	}
 
 	progressCalled := false
-	err := m.InstallPackages(packages, func(msg string, percent float64) {
+	err := m.InstallPackages(context.Background(), packages, func(msg string, percent float64) {
 		progressCalled = true
 
 		assert.True(t, strings.Contains(msg, "already installed") || strings.Contains(msg, "All packages installed successfully!"))
@@ -174,3 +249,25 @@ func TestInstallPackages(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, progressCalled)
 }
+
+func TestInstallPackagesGoesOnPastFailures(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	defer os.Chdir(wd)
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOWORK", "off")
+
+	m := &Manager{}
+	pkgs := []cache.Package{
+		{Name: "a", ImportPath: "github.com/test/a"},
+		{Name: "b", ImportPath: "github.com/test/b"},
+	}
+
+	// outside a module both go get runs fail, and the second one still runs
+	err = m.InstallPackages(context.Background(), pkgs, nil)
+	var installErr InstallError
+	require.ErrorAs(t, err, &installErr)
+	assert.Len(t, installErr, 2)
+	assert.True(t, installErr.Failed("github.com/test/b"))
+}
@@ -0,0 +1,166 @@
+package packages
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+	"github.com/MdSadiqMd/gopick/internal/proxy"
+)
+
+type UpdateKind string
+
+const (
+	UpdatePatch UpdateKind = "patch"
+	UpdateMinor UpdateKind = "minor"
+	// a new major version, which for v2+ lives at a new /vN module path
+	UpdateMajor UpdateKind = "major"
+)
+
+// how many /vN paths above the current one are probed for major updates
+const maxMajorProbes = 10
+
+// Update is a newer version available for a requirement of go.mod
+type Update struct {
+	Path     string `json:"path"`
+	Version  string `json:"version"`
+	Indirect bool   `json:"indirect,omitempty"`
+	// NewPath differs from Path for major updates of v2+ modules
+	NewPath    string     `json:"new_path"`
+	NewVersion string     `json:"new_version"`
+	Kind       UpdateKind `json:"kind"`
+}
+
+// returns the go get argument that applies the update
+func (u Update) Target() string {
+	return u.NewPath + "@" + u.NewVersion
+}
+
+// returns the update as a pinned package that GetInstallCommand and the
+// install paths treat like any other selection
+func (u Update) Package() cache.Package {
+	return cache.Package{
+		Name:            path.Base(u.NewPath),
+		ImportPath:      u.NewPath,
+		ModulePath:      u.NewPath,
+		Version:         u.NewVersion,
+		Pinned:          true,
+		IsInstalled:     u.NewPath == u.Path,
+		InModule:        u.NewPath == u.Path,
+		RequiredVersion: u.Version,
+		Indirect:        u.Indirect,
+	}
+}
+
+// asks the module proxy for newer versions of every requirement of mod.
+// A requirement can have both a minor or patch update and a new major
+// version. Modules the proxy can't answer for are left out
+func (m *Manager) Outdated(mod Module) ([]Update, error) {
+	m.mu.RLock()
+	resolver := m.resolver
+	m.mu.RUnlock()
+	if resolver == nil || resolver.proxy == nil {
+		return nil, proxy.ErrDisabled
+	}
+	client := resolver.proxy
+
+	reqs := mod.Requirements()
+	found := make([][]Update, len(reqs))
+	sem := make(chan struct{}, 8)
+	var wg sync.WaitGroup
+
+	for i, req := range reqs {
+		wg.Add(1)
+		go func(i int, req Requirement) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			found[i] = checkUpdates(client, req)
+		}(i, req)
+	}
+	wg.Wait()
+
+	// in the order of Requirements
+	var updates []Update
+	for _, u := range found {
+		updates = append(updates, u...)
+	}
+
+	return updates, nil
+}
+
+func checkUpdates(client *proxy.Client, req Requirement) []Update {
+	var updates []Update
+
+	if latest, err := client.Latest(req.Path); err == nil && semver.Compare(latest.Version, req.Version) > 0 {
+		updates = append(updates, Update{
+			Path:       req.Path,
+			Version:    req.Version,
+			Indirect:   req.Indirect,
+			NewPath:    req.Path,
+			NewVersion: latest.Version,
+			Kind:       ClassifyUpdate(req.Version, latest.Version),
+		})
+	}
+
+	if newPath, version := latestMajor(client, req.Path); newPath != "" {
+		updates = append(updates, Update{
+			Path:       req.Path,
+			Version:    req.Version,
+			Indirect:   req.Indirect,
+			NewPath:    newPath,
+			NewVersion: version,
+			Kind:       UpdateMajor,
+		})
+	}
+
+	return updates
+}
+
+// classifies the step from one version to another of the same module path
+func ClassifyUpdate(from, to string) UpdateKind {
+	switch {
+	case semver.Major(from) != semver.Major(to):
+		return UpdateMajor
+	case semver.MajorMinor(from) != semver.MajorMinor(to):
+		return UpdateMinor
+	default:
+		return UpdatePatch
+	}
+}
+
+// probes the /vN (or gopkg.in .vN) paths above modulePath and returns the
+// highest one the proxy knows, with its latest version
+func latestMajor(client *proxy.Client, modulePath string) (string, string) {
+	prefix, pathMajor, ok := module.SplitPathVersion(modulePath)
+	if !ok {
+		return "", ""
+	}
+
+	sep := "/"
+	major := 1
+	if pathMajor != "" {
+		sep = pathMajor[:1]
+		if n, err := strconv.Atoi(strings.TrimPrefix(pathMajor[1:], "v")); err == nil {
+			major = n
+		}
+	}
+
+	var newPath, version string
+	for n := major + 1; n <= major+maxMajorProbes; n++ {
+		candidate := prefix + sep + "v" + strconv.Itoa(n)
+		latest, err := client.Latest(candidate)
+		if err != nil {
+			break
+		}
+		newPath, version = candidate, latest.Version
+	}
+
+	return newPath, version
+}
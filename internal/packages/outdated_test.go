@@ -0,0 +1,85 @@
+package packages
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+	"github.com/MdSadiqMd/gopick/internal/proxy"
+)
+
+func TestClassifyUpdate(t *testing.T) {
+	assert.Equal(t, UpdatePatch, ClassifyUpdate("v1.2.0", "v1.2.3"))
+	assert.Equal(t, UpdateMinor, ClassifyUpdate("v1.2.0", "v1.3.0"))
+	assert.Equal(t, UpdateMajor, ClassifyUpdate("v0.9.0", "v1.0.0"))
+	assert.Equal(t, UpdatePatch, ClassifyUpdate("v0.0.0-20230101000000-abcdefabcdef", "v0.0.0-20240101000000-abcdefabcdef"))
+}
+
+func TestOutdated(t *testing.T) {
+	latest := map[string]string{
+		"example.com/patch":      "v1.2.3",
+		"example.com/minor":      "v1.4.0",
+		"example.com/major":      "v1.0.1",
+		"example.com/major/v2":   "v2.5.0",
+		"example.com/major/v3":   "v3.0.0",
+		"example.com/current/v2": "v2.0.0",
+		"gopkg.in/yaml.v2":       "v2.4.0",
+		"gopkg.in/yaml.v3":       "v3.0.1",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		modulePath, ok := strings.CutSuffix(r.URL.Path[1:], "/@latest")
+		if version, known := latest[modulePath]; ok && known {
+			fmt.Fprintf(w, `{"Version":%q}`, version)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	goMod := `module example.com/app
+
+go 1.21
+
+require (
+	example.com/patch v1.2.0
+	example.com/minor v1.2.0
+	example.com/major v1.0.0
+	example.com/current/v2 v2.0.0
+	example.com/private v1.0.0
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+)
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644))
+	project, err := LoadProject(dir)
+	require.NoError(t, err)
+
+	m := New(t.TempDir())
+	_, err = m.Outdated(project.Modules[0])
+	assert.ErrorIs(t, err, proxy.ErrDisabled)
+
+	m.SetResolver(NewResolver(proxy.New(proxy.Settings{GoProxy: server.URL})))
+	updates, err := m.Outdated(project.Modules[0])
+	require.NoError(t, err)
+
+	assert.Equal(t, []Update{
+		{Path: "example.com/major", Version: "v1.0.0", NewPath: "example.com/major", NewVersion: "v1.0.1", Kind: UpdatePatch},
+		{Path: "example.com/major", Version: "v1.0.0", NewPath: "example.com/major/v3", NewVersion: "v3.0.0", Kind: UpdateMajor},
+		{Path: "example.com/minor", Version: "v1.2.0", NewPath: "example.com/minor", NewVersion: "v1.4.0", Kind: UpdateMinor},
+		{Path: "example.com/patch", Version: "v1.2.0", NewPath: "example.com/patch", NewVersion: "v1.2.3", Kind: UpdatePatch},
+		{Path: "gopkg.in/yaml.v2", Version: "v2.4.0", Indirect: true, NewPath: "gopkg.in/yaml.v3", NewVersion: "v3.0.1", Kind: UpdateMajor},
+	}, updates)
+
+	pkg := updates[1].Package()
+	assert.Equal(t, "example.com/major/v3@v3.0.0", updates[1].Target())
+	assert.True(t, NeedsInstall(pkg))
+	assert.Equal(t, "go get example.com/major/v3@v3.0.0 example.com/minor@v1.4.0", m.GetInstallCommand([]cache.Package{pkg, updates[2].Package()}))
+}
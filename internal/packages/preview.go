@@ -0,0 +1,237 @@
+package packages
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+)
+
+// ErrNoProject is returned when an operation needs a go.mod but gopick was
+// started outside a module
+var ErrNoProject = errors.New("not inside a Go module")
+
+type ChangeKind string
+
+const (
+	ChangeAdded      ChangeKind = "added"
+	ChangeUpgraded   ChangeKind = "upgraded"
+	ChangeDowngraded ChangeKind = "downgraded"
+	ChangeRemoved    ChangeKind = "removed"
+)
+
+// Change is a requirement of go.mod that an install would add or move
+type Change struct {
+	Path       string     `json:"path"`
+	Kind       ChangeKind `json:"kind"`
+	OldVersion string     `json:"old_version,omitempty"`
+	NewVersion string     `json:"new_version,omitempty"`
+	Indirect   bool       `json:"indirect,omitempty"`
+	// set for the modules being installed, as opposed to transitive bumps
+	Requested bool `json:"requested,omitempty"`
+}
+
+// Preview describes what installing a set of packages would do to go.mod and go.sum
+type Preview struct {
+	Changes []Change `json:"changes"`
+	// unified diff of go.mod
+	Diff string `json:"diff"`
+	// number of lines go.sum would gain
+	SumAdded int `json:"sum_added"`
+}
+
+// runs go get for pkgs against a scratch copy of go.mod and go.sum via
+// GOFLAGS=-modfile and reports how the requirements would change. The real
+// files are left untouched
+func (m *Manager) PreviewInstall(pkgs []cache.Package) (*Preview, error) {
+	project := m.Project()
+	if project == nil {
+		return nil, ErrNoProject
+	}
+	return m.PreviewInstallIn(project.Modules[0], pkgs)
+}
+
+// like PreviewInstall, for the given module of the workspace
+func (m *Manager) PreviewInstallIn(mod Module, pkgs []cache.Package) (*Preview, error) {
+	requested := make(map[string]bool)
+	var targets []string
+	for _, pkg := range pkgs {
+		if !mod.NeedsInstall(pkg) {
+			continue
+		}
+		target := m.InstallTarget(pkg)
+		targets = append(targets, target)
+
+		modulePath := pkg.ModulePath
+		if modulePath == "" {
+			modulePath = m.ModulePath(pkg.ImportPath)
+		}
+		requested[modulePath] = true
+	}
+
+	oldMod, err := os.ReadFile(mod.GoModPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.mod: %w", err)
+	}
+	oldSum, err := os.ReadFile(filepath.Join(mod.Dir, "go.sum"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read go.sum: %w", err)
+	}
+
+	if len(targets) == 0 {
+		return &Preview{}, nil
+	}
+
+	scratch, err := os.MkdirTemp("", "gopick-preview-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	defer os.RemoveAll(scratch)
+
+	// the go command derives the go.sum path from the -modfile path
+	scratchMod := filepath.Join(scratch, "go.mod")
+	if err := os.WriteFile(scratchMod, oldMod, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write scratch go.mod: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(scratch, "go.sum"), oldSum, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write scratch go.sum: %w", err)
+	}
+
+	cmd := exec.Command("go", append([]string{"get"}, targets...)...)
+	cmd.Dir = mod.Dir
+	// -modfile is not allowed in workspace mode
+	cmd.Env = append(os.Environ(),
+		"GOFLAGS="+strings.TrimSpace(os.Getenv("GOFLAGS")+" -modfile="+scratchMod),
+		"GOWORK=off",
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("go get failed: %w\n%s", err, output)
+	}
+
+	newMod, err := os.ReadFile(scratchMod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scratch go.mod: %w", err)
+	}
+	newSum, _ := os.ReadFile(filepath.Join(scratch, "go.sum"))
+
+	return diffModules(oldMod, newMod, oldSum, newSum, requested)
+}
+
+func diffModules(oldMod, newMod, oldSum, newSum []byte, requested map[string]bool) (*Preview, error) {
+	oldFile, err := modfile.ParseLax("go.mod", oldMod, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+	newFile, err := modfile.ParseLax("go.mod", newMod, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse updated go.mod: %w", err)
+	}
+
+	before := make(map[string]*modfile.Require)
+	for _, r := range oldFile.Require {
+		before[r.Mod.Path] = r
+	}
+	after := make(map[string]*modfile.Require)
+	for _, r := range newFile.Require {
+		after[r.Mod.Path] = r
+	}
+
+	var changes []Change
+	for path, r := range after {
+		change := Change{
+			Path:       path,
+			NewVersion: r.Mod.Version,
+			Indirect:   r.Indirect,
+			Requested:  requested[path],
+		}
+
+		old, ok := before[path]
+		switch {
+		case !ok:
+			change.Kind = ChangeAdded
+		case semver.Compare(r.Mod.Version, old.Mod.Version) > 0:
+			change.Kind = ChangeUpgraded
+			change.OldVersion = old.Mod.Version
+		case semver.Compare(r.Mod.Version, old.Mod.Version) < 0:
+			change.Kind = ChangeDowngraded
+			change.OldVersion = old.Mod.Version
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	for path, r := range before {
+		if _, ok := after[path]; !ok {
+			changes = append(changes, Change{
+				Path:       path,
+				Kind:       ChangeRemoved,
+				OldVersion: r.Mod.Version,
+				Indirect:   r.Indirect,
+			})
+		}
+	}
+
+	// requested modules first, then direct dependencies, then by path
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Requested != b.Requested {
+			return a.Requested
+		}
+		if a.Indirect != b.Indirect {
+			return !a.Indirect
+		}
+		return a.Path < b.Path
+	})
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(oldMod)),
+		B:        difflib.SplitLines(string(newMod)),
+		FromFile: "go.mod",
+		ToFile:   "go.mod",
+		Context:  1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff go.mod: %w", err)
+	}
+
+	return &Preview{
+		Changes:  changes,
+		Diff:     diff,
+		SumAdded: countNewLines(oldSum, newSum),
+	}, nil
+}
+
+func countNewLines(before, after []byte) int {
+	seen := make(map[string]bool)
+	for _, line := range bytes.Split(before, []byte("\n")) {
+		seen[string(line)] = true
+	}
+
+	added := 0
+	for _, line := range bytes.Split(after, []byte("\n")) {
+		if len(line) > 0 && !seen[string(line)] {
+			added++
+		}
+	}
+	return added
+}
+
+// reports whether the preview bumps modules other than the requested ones
+func (p *Preview) HasTransitiveChanges() bool {
+	for _, c := range p.Changes {
+		if !c.Requested && c.Kind != ChangeAdded {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package packages
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+)
+
+// writes a module to a file:// GOPROXY directory
+func writeProxyModule(t *testing.T, proxyDir, modulePath, version, goMod string) {
+	t.Helper()
+	writeProxyModuleFiles(t, proxyDir, modulePath, version, goMod, map[string]string{
+		"lib.go": "package " + filepath.Base(modulePath) + "\n",
+	})
+}
+
+// like writeProxyModule, with the given Go files in the module zip
+func writeProxyModuleFiles(t *testing.T, proxyDir, modulePath, version, goMod string, files map[string]string) {
+	t.Helper()
+
+	dir := filepath.Join(proxyDir, modulePath, "@v")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+
+	list, _ := os.ReadFile(filepath.Join(dir, "list"))
+	list = append(list, []byte(version+"\n")...)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "list"), list, 0644))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, version+".info"),
+		[]byte(fmt.Sprintf(`{"Version":%q,"Time":"2024-01-01T00:00:00Z"}`, version)), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, version+".mod"), []byte(goMod), 0644))
+
+	f, err := os.Create(filepath.Join(dir, version+".zip"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	prefix := modulePath + "@" + version + "/"
+	files["go.mod"] = goMod
+	for name, content := range files {
+		w, err := zw.Create(prefix + name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+}
+
+// sets up an offline module proxy and a project that requires example.com/shared
+func newPreviewProject(t *testing.T) *Manager {
+	t.Helper()
+
+	proxyDir := t.TempDir()
+	writeProxyModule(t, proxyDir, "example.com/shared", "v1.0.0", "module example.com/shared\n\ngo 1.21\n")
+	writeProxyModule(t, proxyDir, "example.com/shared", "v1.1.0", "module example.com/shared\n\ngo 1.21\n")
+	writeProxyModule(t, proxyDir, "example.com/lib", "v1.0.0",
+		"module example.com/lib\n\ngo 1.21\n\nrequire example.com/shared v1.1.0\n")
+
+	t.Setenv("GOPROXY", "file://"+filepath.ToSlash(proxyDir))
+	t.Setenv("GOSUMDB", "off")
+	t.Setenv("GOMODCACHE", t.TempDir())
+	t.Setenv("GOFLAGS", "-modcacherw")
+	t.Setenv("GOTOOLCHAIN", "local")
+
+	projectDir := t.TempDir()
+	goMod := "module example.com/app\n\ngo 1.21\n\nrequire example.com/shared v1.0.0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "go.mod"), []byte(goMod), 0644))
+
+	project, err := LoadProject(projectDir)
+	require.NoError(t, err)
+
+	m := New(t.TempDir())
+	m.SetProject(project)
+	return m
+}
+
+func TestPreviewInstall(t *testing.T) {
+	m := newPreviewProject(t)
+	goModBefore, err := os.ReadFile(m.Project().GoModPath)
+	require.NoError(t, err)
+
+	preview, err := m.PreviewInstall([]cache.Package{
+		{Name: "lib", ImportPath: "example.com/lib", ModulePath: "example.com/lib", Version: "v1.0.0"},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, preview.Changes, 2)
+	assert.Equal(t, Change{
+		Path:       "example.com/lib",
+		Kind:       ChangeAdded,
+		NewVersion: "v1.0.0",
+		// nothing in the project imports it yet
+		Indirect:  true,
+		Requested: true,
+	}, preview.Changes[0])
+	assert.Equal(t, Change{
+		Path:       "example.com/shared",
+		Kind:       ChangeUpgraded,
+		OldVersion: "v1.0.0",
+		NewVersion: "v1.1.0",
+	}, preview.Changes[1])
+	assert.True(t, preview.HasTransitiveChanges())
+
+	assert.Contains(t, preview.Diff, "-require example.com/shared v1.0.0\n+require example.com/shared v1.1.0")
+	assert.Contains(t, preview.Diff, "+require example.com/lib v1.0.0 // indirect")
+	assert.Greater(t, preview.SumAdded, 0)
+
+	// the real files are untouched
+	goModAfter, err := os.ReadFile(m.Project().GoModPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(goModBefore), string(goModAfter))
+	_, err = os.Stat(filepath.Join(m.Project().Dir, "go.sum"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestPreviewInstallErrors(t *testing.T) {
+	_, err := New(t.TempDir()).PreviewInstall([]cache.Package{{ImportPath: "example.com/lib"}})
+	assert.ErrorIs(t, err, ErrNoProject)
+
+	m := newPreviewProject(t)
+	_, err = m.PreviewInstall([]cache.Package{{ImportPath: "example.com/missing", Version: "v1.0.0"}})
+	assert.ErrorContains(t, err, "go get failed")
+}
+
+func TestDiffModules(t *testing.T) {
+	oldMod := []byte("module example.com/app\n\nrequire (\n\texample.com/a v1.2.0\n\texample.com/b v1.0.0 // indirect\n)\n")
+	newMod := []byte("module example.com/app\n\nrequire example.com/a v1.1.0\n")
+
+	preview, err := diffModules(oldMod, newMod, nil, nil, map[string]bool{"example.com/a": true})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Change{
+		{Path: "example.com/a", Kind: ChangeDowngraded, OldVersion: "v1.2.0", NewVersion: "v1.1.0", Requested: true},
+		{Path: "example.com/b", Kind: ChangeRemoved, OldVersion: "v1.0.0", Indirect: true},
+	}, preview.Changes)
+	assert.Equal(t, 0, preview.SumAdded)
+}
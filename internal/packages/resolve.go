@@ -0,0 +1,129 @@
+package packages
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/MdSadiqMd/gopick/internal/proxy"
+)
+
+var majorVersionRe = regexp.MustCompile(`^v([2-9]|[1-9][0-9]+)$`)
+
+// hosts whose module roots are host/owner/repo
+var threeElementHosts = map[string]bool{
+	"github.com":    true,
+	"gitlab.com":    true,
+	"bitbucket.org": true,
+	"golang.org":    true,
+}
+
+// Resolver maps package import paths to the module that provides them
+type Resolver struct {
+	proxy *proxy.Client
+
+	mu      sync.Mutex
+	modules map[string]bool // module path -> known to the proxy
+}
+
+// creates a resolver; with a nil client only the path heuristics are used
+func NewResolver(client *proxy.Client) *Resolver {
+	return &Resolver{
+		proxy:   client,
+		modules: make(map[string]bool),
+	}
+}
+
+// returns the module path that most likely provides importPath
+func (r *Resolver) Resolve(importPath string) string {
+	root := HeuristicModulePath(importPath)
+	if r == nil || r.proxy == nil || root == importPath {
+		return root
+	}
+
+	// nested modules (e.g. cloud.google.com/go/storage) live below the
+	// heuristic root, so ask the proxy from the longest candidate down
+	for candidate := importPath; len(candidate) > len(root); candidate = path.Dir(candidate) {
+		if r.isModule(candidate) {
+			return candidate
+		}
+	}
+
+	return root
+}
+
+// returns the module path for importPath from what earlier Resolve calls
+// learned, or the heuristic root. It never contacts the proxy
+func (r *Resolver) Known(importPath string) string {
+	root := HeuristicModulePath(importPath)
+	if r == nil || root == importPath {
+		return root
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for candidate := importPath; len(candidate) > len(root); candidate = path.Dir(candidate) {
+		if r.modules[candidate] {
+			return candidate
+		}
+	}
+	return root
+}
+
+// reports whether modulePath is a module. Proxies may answer @v/list with an
+// empty list for any path, package directories included, so an empty list
+// only counts when @latest resolves too
+func (r *Resolver) isModule(modulePath string) bool {
+	r.mu.Lock()
+	known, ok := r.modules[modulePath]
+	r.mu.Unlock()
+	if ok {
+		return known
+	}
+
+	versions, err := r.proxy.List(modulePath)
+	known = err == nil && len(versions) > 0
+	if err == nil && !known {
+		_, err = r.proxy.Latest(modulePath)
+		known = err == nil
+	}
+
+	r.mu.Lock()
+	r.modules[modulePath] = known
+	r.mu.Unlock()
+
+	return known
+}
+
+// guesses the module root of importPath from its shape alone, including
+// gopkg.in paths and /vN major version suffixes
+func HeuristicModulePath(importPath string) string {
+	parts := strings.Split(importPath, "/")
+
+	// standard library and other dotless paths are not modules
+	if !strings.Contains(parts[0], ".") {
+		return importPath
+	}
+
+	n := 2
+	switch {
+	case parts[0] == "gopkg.in":
+		// gopkg.in/yaml.v3 or gopkg.in/user/pkg.v1
+		if len(parts) > 1 && !strings.Contains(parts[1], ".v") {
+			n = 3
+		}
+	case threeElementHosts[parts[0]]:
+		n = 3
+	}
+
+	if len(parts) <= n {
+		return importPath
+	}
+
+	if majorVersionRe.MatchString(parts[n]) {
+		n++
+	}
+
+	return strings.Join(parts[:n], "/")
+}
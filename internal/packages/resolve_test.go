@@ -0,0 +1,115 @@
+package packages
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/MdSadiqMd/gopick/internal/proxy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeuristicModulePath(t *testing.T) {
+	tests := []struct {
+		importPath string
+		expected   string
+	}{
+		{"github.com/spf13/cobra", "github.com/spf13/cobra"},
+		{"github.com/spf13/cobra/doc", "github.com/spf13/cobra"},
+		{"github.com/go-chi/chi/v5/middleware", "github.com/go-chi/chi/v5"},
+		{"github.com/go-chi/chi/v5", "github.com/go-chi/chi/v5"},
+		{"golang.org/x/text/language", "golang.org/x/text"},
+		{"gopkg.in/yaml.v3", "gopkg.in/yaml.v3"},
+		{"gopkg.in/src-d/go-git.v4/plumbing", "gopkg.in/src-d/go-git.v4"},
+		{"go.uber.org/zap/zapcore", "go.uber.org/zap"},
+		{"k8s.io/client-go/v12/kubernetes", "k8s.io/client-go/v12"},
+		{"github.com/test/pkg/v1", "github.com/test/pkg"},
+		{"encoding/json", "encoding/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.importPath, func(t *testing.T) {
+			assert.Equal(t, tt.expected, HeuristicModulePath(tt.importPath))
+		})
+	}
+}
+
+func TestResolverWithProxy(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path == "/cloud.google.com/go/storage/@v/list" {
+			w.Write([]byte("v1.30.0\n"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	r := NewResolver(proxy.New(proxy.Settings{GoProxy: server.URL}))
+
+	assert.Equal(t, "cloud.google.com/go/storage", r.Resolve("cloud.google.com/go/storage/internal/apiv2"))
+	assert.Equal(t, "cloud.google.com/go", r.Resolve("cloud.google.com/go/civil"))
+
+	// roots never hit the proxy and lookups are memoized
+	before := atomic.LoadInt32(&requests)
+	r.Resolve("github.com/spf13/cobra")
+	r.Resolve("cloud.google.com/go/storage/internal/apiv2")
+	assert.Equal(t, before, atomic.LoadInt32(&requests))
+}
+
+func TestResolverSkipsEmptyLists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/golang.org/x/text/language/@v/list":
+			// some proxies answer any path with an empty list
+		case "/golang.org/x/text/@v/list":
+			w.Write([]byte("v0.14.0\n"))
+		case "/github.com/test/untagged/sub/@v/list":
+		case "/github.com/test/untagged/sub/@latest":
+			w.Write([]byte(`{"Version":"v0.0.0-20240101000000-abcdefabcdef"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	r := NewResolver(proxy.New(proxy.Settings{GoProxy: server.URL}))
+
+	assert.Equal(t, "golang.org/x/text", r.Resolve("golang.org/x/text/language"))
+	// an untagged nested module still counts once @latest resolves
+	assert.Equal(t, "github.com/test/untagged/sub", r.Resolve("github.com/test/untagged/sub"))
+}
+
+func TestResolverWithoutProxy(t *testing.T) {
+	var r *Resolver
+	assert.Equal(t, "golang.org/x/text", r.Resolve("golang.org/x/text/language"))
+
+	r = NewResolver(nil)
+	assert.Equal(t, "golang.org/x/text", r.Resolve("golang.org/x/text/language"))
+}
+
+func TestModulePathWaitsForResolveModules(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path == "/cloud.google.com/go/storage/@v/list" {
+			w.Write([]byte("v1.30.0\n"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	m := New(t.TempDir())
+	m.SetResolver(NewResolver(proxy.New(proxy.Settings{GoProxy: server.URL})))
+
+	// without a lookup the heuristic root is used and the proxy is left alone
+	assert.Equal(t, "cloud.google.com/go", m.ModulePath("cloud.google.com/go/storage/internal"))
+	assert.Zero(t, atomic.LoadInt32(&requests))
+
+	m.ResolveModules([]string{"cloud.google.com/go/storage/internal", "github.com/spf13/cobra"})
+	assert.Equal(t, "cloud.google.com/go/storage", m.ModulePath("cloud.google.com/go/storage/internal"))
+	assert.Equal(t, "github.com/spf13/cobra", m.ModulePath("github.com/spf13/cobra"))
+}
@@ -0,0 +1,110 @@
+package packages
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	stdlibOnce  sync.Once
+	stdlibPaths map[string]bool
+)
+
+// reports whether importPath belongs to the standard library of the active
+// Go version. The list comes from go list std, or from GOROOT/src when the go
+// command fails; without either, paths whose first element has no dot count
+func (m *Manager) IsStdlib(importPath string) bool {
+	m.mu.RLock()
+	std := m.stdlib
+	m.mu.RUnlock()
+
+	if std == nil {
+		// the active Go version doesn't change while gopick runs
+		stdlibOnce.Do(func() { stdlibPaths = m.loadStdlib() })
+		std = stdlibPaths
+		m.mu.Lock()
+		m.stdlib = std
+		m.mu.Unlock()
+	}
+
+	if len(std) == 0 {
+		first, _, _ := strings.Cut(importPath, "/")
+		return first != "" && !strings.Contains(first, ".")
+	}
+	return std[importPath]
+}
+
+// overrides the standard library packages, e.g. with those of another Go
+// version. An empty list falls back to the path heuristic
+func (m *Manager) SetStdlib(paths []string) {
+	std := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		std[p] = true
+	}
+
+	m.mu.Lock()
+	m.stdlib = std
+	m.mu.Unlock()
+}
+
+func (m *Manager) loadStdlib() map[string]bool {
+	std := make(map[string]bool)
+
+	output, err := exec.Command("go", "list", "std").Output()
+	if err == nil {
+		for _, line := range strings.Fields(string(output)) {
+			std[line] = true
+		}
+		return std
+	}
+
+	goroot := os.Getenv("GOROOT")
+	if goroot == "" {
+		goroot, _ = m.GetGoEnv("GOROOT")
+	}
+	if goroot == "" {
+		return std
+	}
+
+	src := filepath.Join(goroot, "src")
+	filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case "testdata", "cmd", "vendor":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, "_test.go") {
+			if rel, err := filepath.Rel(src, filepath.Dir(path)); err == nil && rel != "." {
+				std[filepath.ToSlash(rel)] = true
+			}
+		}
+		return nil
+	})
+	return std
+}
+
+// returns the Go source that imports paths: an import declaration for one
+// path and an import block for several
+func ImportLines(paths []string) string {
+	if len(paths) == 1 {
+		return "import " + strconv.Quote(paths[0])
+	}
+
+	var b strings.Builder
+	b.WriteString("import (\n")
+	for _, p := range paths {
+		b.WriteString("\t" + strconv.Quote(p) + "\n")
+	}
+	b.WriteString(")")
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package packages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+)
+
+func TestIsStdlib(t *testing.T) {
+	m := New(t.TempDir())
+	assert.True(t, m.IsStdlib("encoding/json"))
+	assert.True(t, m.IsStdlib("net/http"))
+	assert.False(t, m.IsStdlib("github.com/gin-gonic/gin"))
+
+	// another Go version's list replaces the detected one
+	m.SetStdlib([]string{"log/slog"})
+	assert.True(t, m.IsStdlib("log/slog"))
+	assert.False(t, m.IsStdlib("encoding/json"))
+
+	// without a list, paths without a domain count as stdlib
+	m.SetStdlib(nil)
+	assert.True(t, m.IsStdlib("encoding/json"))
+	assert.False(t, m.IsStdlib("golang.org/x/mod"))
+}
+
+func TestStdlibIsNeverInstalled(t *testing.T) {
+	m := New(t.TempDir())
+	m.SetStdlib([]string{"encoding/json"})
+
+	pkgs := m.MarkInstalledPackages([]cache.Package{
+		{ImportPath: "encoding/json", Version: "v1.0.0", Pinned: true},
+		{ImportPath: "github.com/test/pkg"},
+	})
+	assert.True(t, pkgs[0].Stdlib)
+	assert.False(t, pkgs[1].Stdlib)
+	assert.False(t, NeedsInstall(pkgs[0]))
+	assert.False(t, Module{}.NeedsInstall(pkgs[0]))
+	assert.Equal(t, "go get github.com/test/pkg", m.GetInstallCommand(pkgs))
+	assert.Empty(t, m.GetInstallCommand(pkgs[:1]))
+}
+
+func TestImportLines(t *testing.T) {
+	assert.Equal(t, `import "encoding/json"`, ImportLines([]string{"encoding/json"}))
+	assert.Equal(t, "import (\n\t\"encoding/json\"\n\t\"net/http\"\n)", ImportLines([]string{"encoding/json", "net/http"}))
+}
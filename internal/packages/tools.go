@@ -0,0 +1,328 @@
+package packages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+)
+
+// returns the directory go install writes binaries to: GOBIN, or the bin
+// directory of the first GOPATH entry
+func (m *Manager) GoBin() string {
+	m.mu.RLock()
+	goBin := m.goBin
+	m.mu.RUnlock()
+	if goBin != "" {
+		return goBin
+	}
+
+	goBin, _ = m.GetGoEnv("GOBIN")
+	if goBin == "" {
+		gopath, _ := m.GetGoEnv("GOPATH")
+		if list := filepath.SplitList(gopath); len(list) > 0 && list[0] != "" {
+			goBin = filepath.Join(list[0], "bin")
+		} else if home, err := os.UserHomeDir(); err == nil {
+			goBin = filepath.Join(home, "go", "bin")
+		}
+	}
+
+	m.SetGoBin(goBin)
+	return goBin
+}
+
+// overrides the directory checked for installed binaries
+func (m *Manager) SetGoBin(dir string) {
+	m.mu.Lock()
+	m.goBin = dir
+	m.mu.Unlock()
+}
+
+// returns the name go install gives the binary of importPath, which skips
+// a trailing major version element
+func BinaryName(importPath string) string {
+	name := path.Base(importPath)
+	if dir := path.Dir(importPath); dir != "." && majorVersionRe.MatchString(name) {
+		name = path.Base(dir)
+	}
+	return name
+}
+
+// reports whether the binary of the command importPath exists in GOBIN
+func (m *Manager) IsBinaryInstalled(importPath string) bool {
+	goBin := m.GoBin()
+	if goBin == "" {
+		return false
+	}
+
+	name := BinaryName(importPath)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
+	info, err := os.Stat(filepath.Join(goBin, name))
+	return err == nil && !info.IsDir()
+}
+
+// reports whether pkg is a package main, asking the module proxy when the
+// search result did not say. Lookups are remembered for MarkInstalledPackages
+func (m *Manager) DetectCommand(pkg cache.Package) (bool, error) {
+	if pkg.Command {
+		return true, nil
+	}
+
+	m.mu.RLock()
+	known, ok := m.commands[pkg.ImportPath]
+	resolver := m.resolver
+	m.mu.RUnlock()
+	if ok {
+		return known, nil
+	}
+	if resolver == nil || resolver.proxy == nil {
+		return false, nil
+	}
+
+	modulePath := pkg.ModulePath
+	if modulePath == "" {
+		modulePath = m.ModulePath(pkg.ImportPath)
+	}
+
+	version := CanonicalVersion(pkg.Version)
+	if !semver.IsValid(version) {
+		latest, err := resolver.proxy.Latest(modulePath)
+		if err != nil {
+			return false, fmt.Errorf("failed to resolve %s: %w", modulePath, err)
+		}
+		version = latest.Version
+	}
+
+	name, err := resolver.proxy.PackageName(modulePath, version, pkg.ImportPath)
+	if err != nil {
+		return false, err
+	}
+
+	known = name == "main"
+	m.mu.Lock()
+	m.commands[pkg.ImportPath] = known
+	m.mu.Unlock()
+
+	return known, nil
+}
+
+func (m *Manager) isCommand(pkg cache.Package) bool {
+	if pkg.Command {
+		return true
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.commands[pkg.ImportPath]
+}
+
+// reports whether the go command is new enough (1.24) for go get -tool
+func (m *Manager) SupportsToolDirective() bool {
+	version := m.GoVersion()
+	return version != "" && semver.Compare(version, "v1.24") >= 0
+}
+
+// returns the version of the go command as semver, e.g. v1.24.1, or "" when
+// it can't be told
+func (m *Manager) GoVersion() string {
+	m.mu.RLock()
+	version, ok := m.goVersion, m.goVersionLoaded
+	m.mu.RUnlock()
+	if ok {
+		return version
+	}
+
+	goVersion, _ := m.GetGoEnv("GOVERSION")
+	version = toolchainSemver(goVersion)
+
+	m.mu.Lock()
+	m.goVersion, m.goVersionLoaded = version, true
+	m.mu.Unlock()
+	return version
+}
+
+// overrides the version of the go command, as semver
+func (m *Manager) SetGoVersion(version string) {
+	m.mu.Lock()
+	m.goVersion, m.goVersionLoaded = version, true
+	m.mu.Unlock()
+}
+
+// converts a toolchain name like go1.24.1 or go1.25rc1 to semver
+func toolchainSemver(goVersion string) string {
+	v, ok := strings.CutPrefix(goVersion, "go")
+	if !ok {
+		return ""
+	}
+	if i := strings.IndexFunc(v, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); i >= 0 {
+		v = v[:i]
+	}
+	v = "v" + strings.TrimSuffix(v, ".")
+	if !semver.IsValid(v) {
+		return ""
+	}
+	return v
+}
+
+// reports whether the command pkg should be passed to go install; binaries
+// already in GOBIN are skipped unless a version was pinned
+func NeedsToolInstall(pkg cache.Package) bool {
+	return !pkg.InGoBin || pkg.Pinned
+}
+
+// returns the go install argument for pkg. Unlike go get, go install takes
+// the package path with the version of its module
+func ToolTarget(pkg cache.Package) string {
+	version := CanonicalVersion(pkg.Version)
+	if !pkg.Pinned || version == "" {
+		version = "latest"
+	}
+	return pkg.ImportPath + "@" + version
+}
+
+// returns the go get -tool argument for pkg, which keeps the version go
+// get picks unless one was pinned
+func ToolDirectiveTarget(pkg cache.Package) string {
+	if pkg.Pinned && pkg.Version != "" {
+		return pkg.ImportPath + "@" + CanonicalVersion(pkg.Version)
+	}
+	return pkg.ImportPath
+}
+
+// returns the go install commands for the commands among packages. go
+// install only accepts several arguments from the same module, so each
+// binary gets its own command
+func (m *Manager) GetToolInstallCommand(packages []cache.Package) string {
+	var commands []string
+	for _, pkg := range packages {
+		if m.isCommand(pkg) && NeedsToolInstall(pkg) {
+			commands = append(commands, "go install "+ToolTarget(pkg))
+		}
+	}
+	return strings.Join(commands, " && ")
+}
+
+// returns the go get -tool command recording the commands among packages
+// as tools of the current module, skipping those that already are
+func (m *Manager) GetToolDirectiveCommand(packages []cache.Package) string {
+	var targets []string
+	for _, pkg := range packages {
+		if !m.isCommand(pkg) || (pkg.Tool && !pkg.Pinned) {
+			continue
+		}
+		targets = append(targets, ToolDirectiveTarget(pkg))
+	}
+
+	if len(targets) == 0 {
+		return ""
+	}
+	return "go get -tool " + strings.Join(targets, " ")
+}
+
+// runs go install for target, a command path followed by @version
+func (m *Manager) InstallTool(ctx context.Context, target string, progress func(string)) error {
+	if progress != nil {
+		progress(fmt.Sprintf("Installing %s...", target))
+	}
+
+	if err := runGo(ctx, "", []string{"install", target}, progress); err != nil {
+		return fmt.Errorf("installation failed: %w", err)
+	}
+
+	if progress != nil {
+		progress(fmt.Sprintf("✓ %s installed to %s", target, m.GoBin()))
+	}
+
+	return nil
+}
+
+// ErrToolsUnsupported is returned when adding a tool directive with a go
+// command older than 1.24
+var ErrToolsUnsupported = errors.New("tool directives need Go 1.24 or newer")
+
+// adds target (a command path, optionally with @version) as a tool of mod by
+// running go get -tool, which also requires its module
+func (m *Manager) AddTool(ctx context.Context, mod Module, target string, progress func(string)) error {
+	if !m.SupportsToolDirective() {
+		return ErrToolsUnsupported
+	}
+
+	if progress != nil {
+		progress(fmt.Sprintf("Adding tool %s...", target))
+	}
+
+	if err := runGo(ctx, mod.Dir, []string{"get", "-tool", target}, progress); err != nil {
+		return fmt.Errorf("failed to add tool: %w", err)
+	}
+	m.RefreshCache()
+
+	if progress != nil {
+		progress(fmt.Sprintf("✓ %s added to the tools of %s", target, mod.Path))
+	}
+
+	return nil
+}
+
+// deletes the tool directive for importPath from the go.mod of mod. The
+// requirement stays until the next go mod tidy, like with go get -tool @none
+func (m *Manager) RemoveTool(mod Module, importPath string) error {
+	data, err := os.ReadFile(mod.GoModPath)
+	if err != nil {
+		return fmt.Errorf("failed to read go.mod: %w", err)
+	}
+
+	mf, err := modfile.ParseLax(mod.GoModPath, data, nil)
+	if err != nil {
+		return fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+
+	found := false
+	forEachTool(mf.Syntax, func(path string, line *modfile.Line) {
+		if path == importPath {
+			line.Token = nil
+			line.Suffix = nil
+			found = true
+		}
+	})
+	if !found {
+		return fmt.Errorf("%s is not a tool of %s", importPath, mod.Path)
+	}
+	mf.Syntax.Cleanup()
+
+	if err := writeFileAtomic(mod.GoModPath, modfile.Format(mf.Syntax)); err != nil {
+		return fmt.Errorf("failed to write go.mod: %w", err)
+	}
+	m.RefreshCache()
+
+	return nil
+}
+
+// replaces path through a temporary file so that go.mod is never left half written
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, mode); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package packages
+
+import (
+	"archive/zip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+	"github.com/MdSadiqMd/gopick/internal/proxy"
+)
+
+func TestBinaryName(t *testing.T) {
+	assert.Equal(t, "stringer", BinaryName("golang.org/x/tools/cmd/stringer"))
+	assert.Equal(t, "golangci-lint", BinaryName("github.com/golangci/golangci-lint/v2/cmd/golangci-lint"))
+	assert.Equal(t, "mockgen", BinaryName("github.com/golang/mock/mockgen/v2"))
+	assert.Equal(t, "gopls", BinaryName("gopls"))
+}
+
+func TestIsBinaryInstalled(t *testing.T) {
+	goBin := t.TempDir()
+	m := New(t.TempDir())
+	m.SetGoBin(goBin)
+
+	name := "stringer"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(goBin, name), nil, 0755))
+
+	assert.True(t, m.IsBinaryInstalled("golang.org/x/tools/cmd/stringer"))
+	assert.False(t, m.IsBinaryInstalled("golang.org/x/tools/cmd/goimports"))
+
+	marked := m.MarkInstalledPackages([]cache.Package{
+		{ImportPath: "golang.org/x/tools/cmd/stringer", Command: true},
+		{ImportPath: "golang.org/x/tools/cmd/goimports", Command: true},
+		{ImportPath: "golang.org/x/tools/go/packages"},
+	})
+	assert.True(t, marked[0].InGoBin)
+	assert.False(t, marked[1].InGoBin)
+	assert.False(t, marked[2].Command)
+}
+
+func TestToolchainSemver(t *testing.T) {
+	assert.Equal(t, "v1.24.1", toolchainSemver("go1.24.1"))
+	assert.Equal(t, "v1.25", toolchainSemver("go1.25rc1"))
+	assert.Equal(t, "v1.24", toolchainSemver("go1.24"))
+	assert.Equal(t, "", toolchainSemver("devel go1.25-abcdef"))
+	assert.Equal(t, "", toolchainSemver(""))
+}
+
+func TestGetToolInstallCommand(t *testing.T) {
+	m := New(t.TempDir())
+
+	pkgs := []cache.Package{
+		{ImportPath: "golang.org/x/tools/cmd/stringer", Command: true, Version: "0.20.0"},
+		{ImportPath: "github.com/golang/mock/mockgen", Command: true, Version: "1.6.0", Pinned: true},
+		{ImportPath: "github.com/air-verse/air", Command: true, InGoBin: true},
+		{ImportPath: "github.com/spf13/cobra"},
+	}
+
+	assert.Equal(t,
+		"go install golang.org/x/tools/cmd/stringer@latest && go install github.com/golang/mock/mockgen@v1.6.0",
+		m.GetToolInstallCommand(pkgs))
+	assert.Equal(t,
+		"go get -tool golang.org/x/tools/cmd/stringer github.com/golang/mock/mockgen@v1.6.0 github.com/air-verse/air",
+		m.GetToolDirectiveCommand(pkgs))
+
+	assert.Empty(t, m.GetToolInstallCommand(pkgs[2:]))
+	assert.Empty(t, m.GetToolDirectiveCommand(pkgs[3:]))
+}
+
+func TestDetectCommand(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch r.URL.Path {
+		case "/example.com/tools/@latest":
+			w.Write([]byte(`{"Version":"v1.0.0"}`))
+		case "/example.com/tools/@v/v1.0.0.zip":
+			zw := zip.NewWriter(w)
+			for name, content := range map[string]string{
+				"example.com/tools@v1.0.0/go.mod":          "module example.com/tools\n",
+				"example.com/tools@v1.0.0/tools.go":        "package tools\n",
+				"example.com/tools@v1.0.0/cmd/gen/main.go": "package main\n",
+			} {
+				f, _ := zw.Create(name)
+				f.Write([]byte(content))
+			}
+			zw.Close()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	m := New(t.TempDir())
+	m.SetResolver(NewResolver(proxy.New(proxy.Settings{GoProxy: server.URL})))
+
+	gen := cache.Package{ImportPath: "example.com/tools/cmd/gen", ModulePath: "example.com/tools"}
+	isCommand, err := m.DetectCommand(gen)
+	require.NoError(t, err)
+	assert.True(t, isCommand)
+
+	isCommand, err = m.DetectCommand(cache.Package{ImportPath: "example.com/tools", ModulePath: "example.com/tools", Version: "v1.0.0"})
+	require.NoError(t, err)
+	assert.False(t, isCommand)
+
+	// detections are remembered and applied to later results
+	before := atomic.LoadInt32(&requests)
+	isCommand, err = m.DetectCommand(gen)
+	require.NoError(t, err)
+	assert.True(t, isCommand)
+	assert.Equal(t, before, atomic.LoadInt32(&requests))
+
+	m.SetGoBin(t.TempDir())
+	marked := m.MarkInstalledPackages([]cache.Package{gen})
+	assert.True(t, marked[0].Command)
+	assert.False(t, marked[0].InGoBin)
+}
+
+func TestLoadProjectTools(t *testing.T) {
+	dir := t.TempDir()
+	goMod := "module example.com/app\n\ngo 1.24\n\ntool example.com/gen\n\ntool (\n\texample.com/tools/cmd/a\n\t\"example.com/tools/cmd/b\"\n)\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644))
+
+	project, err := LoadProject(dir)
+	require.NoError(t, err)
+
+	mod := project.Modules[0]
+	assert.Equal(t, []string{"example.com/gen", "example.com/tools/cmd/a", "example.com/tools/cmd/b"}, mod.Tools)
+	assert.True(t, mod.HasTool("example.com/tools/cmd/b"))
+	assert.False(t, mod.HasTool("example.com/tools"))
+}
+
+func TestRemoveTool(t *testing.T) {
+	dir := t.TempDir()
+	goModPath := filepath.Join(dir, "go.mod")
+	goMod := "module example.com/app\n\ngo 1.24\n\ntool (\n\texample.com/tools/cmd/a // codegen\n\texample.com/tools/cmd/b\n)\n\nrequire example.com/tools v1.0.0\n"
+	require.NoError(t, os.WriteFile(goModPath, []byte(goMod), 0644))
+
+	project, err := LoadProject(dir)
+	require.NoError(t, err)
+	m := New(t.TempDir())
+	m.SetProject(project)
+
+	require.NoError(t, m.RemoveTool(project.Modules[0], "example.com/tools/cmd/a"))
+	data, err := os.ReadFile(goModPath)
+	require.NoError(t, err)
+	assert.Equal(t, "module example.com/app\n\ngo 1.24\n\ntool example.com/tools/cmd/b\n\nrequire example.com/tools v1.0.0\n", string(data))
+	assert.Equal(t, []string{"example.com/tools/cmd/b"}, m.Project().Modules[0].Tools)
+
+	require.NoError(t, m.RemoveTool(m.Project().Modules[0], "example.com/tools/cmd/b"))
+	assert.Empty(t, m.Project().Modules[0].Tools)
+
+	err = m.RemoveTool(m.Project().Modules[0], "example.com/tools/cmd/b")
+	assert.ErrorContains(t, err, "is not a tool")
+}
+
+func TestAddTool(t *testing.T) {
+	m := newPreviewProject(t)
+	if !m.SupportsToolDirective() {
+		t.Skip("go get -tool needs Go 1.24")
+	}
+
+	proxyDir := filepath.FromSlash(strings.TrimPrefix(os.Getenv("GOPROXY"), "file://"))
+	writeProxyModuleFiles(t, proxyDir, "example.com/gen", "v1.0.0", "module example.com/gen\n\ngo 1.21\n", map[string]string{
+		"main.go": "package main\n\nfunc main() {}\n",
+	})
+
+	require.NoError(t, m.AddTool(context.Background(), m.Project().Modules[0], "example.com/gen@v1.0.0", nil))
+
+	mod := m.Project().Modules[0]
+	assert.Equal(t, []string{"example.com/gen"}, mod.Tools)
+	req, ok := mod.Lookup("example.com/gen")
+	require.True(t, ok)
+	assert.Equal(t, "v1.0.0", req.Version)
+}
@@ -0,0 +1,155 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+)
+
+// Rule names modules by path prefix, in the GOPRIVATE pattern syntax
+type Rule struct {
+	Module string `json:"module"`
+	Reason string `json:"reason,omitempty"`
+	// what to use instead, e.g. "log/slog"
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// Policy is an organization's list of banned and allowed modules
+type Policy struct {
+	// when set, only modules matching one of these may be installed
+	Allow []Rule `json:"allow,omitempty"`
+	// banned modules; these win over Allow
+	Deny []Rule `json:"deny,omitempty"`
+}
+
+// Status is the verdict of a policy for one import path
+type Status struct {
+	Banned      bool
+	Reason      string
+	Replacement string
+}
+
+// explains the verdict, e.g. "unmaintained; use log/slog instead"
+func (s Status) Message() string {
+	msg := s.Reason
+	if s.Replacement != "" {
+		if msg != "" {
+			msg += "; "
+		}
+		msg += "use " + s.Replacement + " instead"
+	}
+	return msg
+}
+
+// reads the policy from a local path, a file:// URL or an http(s) URL. A
+// fetched policy is copied to cacheDir and that copy is used when the URL
+// can't be reached, so it keeps working offline
+func Load(source, cacheDir string) (*Policy, error) {
+	var data []byte
+	var err error
+
+	switch {
+	case strings.HasPrefix(source, "http://"), strings.HasPrefix(source, "https://"):
+		cached := filepath.Join(cacheDir, "policy.json")
+		data, err = fetch(source)
+		if err == nil {
+			os.WriteFile(cached, data, 0644)
+		} else if saved, cacheErr := os.ReadFile(cached); cacheErr == nil {
+			data, err = saved, nil
+		}
+	case strings.HasPrefix(source, "file://"):
+		u, parseErr := url.Parse(source)
+		if parseErr != nil {
+			return nil, fmt.Errorf("invalid policy URL %q: %w", source, parseErr)
+		}
+		data, err = os.ReadFile(filepath.FromSlash(u.Path))
+	default:
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	return &p, nil
+}
+
+func fetch(u string) ([]byte, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from %s: %d", u, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// returns the verdict for importPath. A nil policy allows everything, and
+// so does any policy for the standard library, which is never installed
+func (p *Policy) Check(importPath string) Status {
+	if p == nil || isStdlib(importPath) {
+		return Status{}
+	}
+
+	for _, r := range p.Deny {
+		if module.MatchPrefixPatterns(r.Module, importPath) {
+			return Status{Banned: true, Reason: r.Reason, Replacement: r.Replacement}
+		}
+	}
+
+	if len(p.Allow) == 0 {
+		return Status{}
+	}
+	for _, r := range p.Allow {
+		if module.MatchPrefixPatterns(r.Module, importPath) {
+			return Status{}
+		}
+	}
+	return Status{Banned: true, Reason: "not on the allowlist"}
+}
+
+// standard library paths have no dot in their first element
+func isStdlib(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// sets the policy status of each package
+func (p *Policy) Apply(pkgs []cache.Package) {
+	for i := range pkgs {
+		var status Status
+		if !pkgs[i].Stdlib {
+			status = p.Check(pkgs[i].ImportPath)
+		}
+		pkgs[i].Banned = status.Banned
+		pkgs[i].PolicyReason = status.Message()
+	}
+}
+
+// returns the banned packages among pkgs
+func Banned(pkgs []cache.Package) []cache.Package {
+	var banned []cache.Package
+	for _, pkg := range pkgs {
+		if pkg.Banned {
+			banned = append(banned, pkg)
+		}
+	}
+	return banned
+}
@@ -0,0 +1,109 @@
+package policy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+)
+
+const testPolicy = `{
+  "deny": [
+    {"module": "github.com/sirupsen/logrus", "reason": "in maintenance mode", "replacement": "log/slog"},
+    {"module": "github.com/evil/*"}
+  ]
+}`
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.json")
+	require.NoError(t, os.WriteFile(path, []byte(testPolicy), 0644))
+
+	for _, source := range []string{path, "file://" + filepath.ToSlash(path)} {
+		p, err := Load(source, t.TempDir())
+		require.NoError(t, err)
+		assert.Len(t, p.Deny, 2)
+	}
+
+	_, err := Load(filepath.Join(dir, "missing.json"), t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestLoadURLFallsBackToCachedCopy(t *testing.T) {
+	up := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(testPolicy))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	p, err := Load(server.URL+"/policy.json", cacheDir)
+	require.NoError(t, err)
+	assert.Len(t, p.Deny, 2)
+
+	up = false
+	p, err = Load(server.URL+"/policy.json", cacheDir)
+	require.NoError(t, err)
+	assert.Len(t, p.Deny, 2)
+
+	_, err = Load(server.URL+"/policy.json", t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	p := &Policy{
+		Allow: []Rule{{Module: "github.com/sirupsen/logrus"}, {Module: "github.com/acme"}, {Module: "github.com/evil/*"}},
+		Deny: []Rule{
+			{Module: "github.com/sirupsen/logrus", Reason: "in maintenance mode", Replacement: "log/slog"},
+			{Module: "github.com/evil/*"},
+		},
+	}
+
+	status := p.Check("github.com/sirupsen/logrus/hooks/syslog")
+	assert.True(t, status.Banned)
+	assert.Equal(t, "in maintenance mode; use log/slog instead", status.Message())
+
+	assert.True(t, p.Check("github.com/evil/tool").Banned)
+	assert.False(t, p.Check("github.com/acme/lib").Banned)
+	assert.True(t, p.Check("github.com/acmecorp/lib").Banned, "prefixes match whole path elements")
+	assert.Equal(t, "not on the allowlist", p.Check("github.com/other/lib").Message())
+
+	var none *Policy
+	assert.False(t, none.Check("github.com/evil/tool").Banned)
+}
+
+func TestStdlibIsNeverBanned(t *testing.T) {
+	p := &Policy{Allow: []Rule{{Module: "github.com/acme"}}}
+	assert.False(t, p.Check("log/slog").Banned)
+	assert.True(t, p.Check("github.com/other/lib").Banned)
+
+	pkgs := []cache.Package{{ImportPath: "log/slog", Stdlib: true}, {ImportPath: "encoding/json", Stdlib: true}}
+	p.Apply(pkgs)
+	assert.Empty(t, Banned(pkgs))
+}
+
+func TestApply(t *testing.T) {
+	p := &Policy{Deny: []Rule{{Module: "github.com/sirupsen/logrus", Replacement: "log/slog"}}}
+	pkgs := []cache.Package{
+		{ImportPath: "github.com/sirupsen/logrus"},
+		// stale status from the search cache is reset
+		{ImportPath: "github.com/rs/zerolog", Banned: true, PolicyReason: "old"},
+	}
+
+	p.Apply(pkgs)
+	assert.True(t, pkgs[0].Banned)
+	assert.Equal(t, "use log/slog instead", pkgs[0].PolicyReason)
+	assert.False(t, pkgs[1].Banned)
+	assert.Empty(t, pkgs[1].PolicyReason)
+	assert.Len(t, Banned(pkgs), 1)
+}
@@ -0,0 +1,327 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+const DefaultURL = "https://proxy.golang.org"
+
+var (
+	// ErrPrivate is returned for modules matched by GONOPROXY/GOPRIVATE
+	ErrPrivate = errors.New("module is private and not fetched through a proxy")
+	// ErrDisabled is returned when GOPROXY is "off" or only lists "direct"
+	ErrDisabled = errors.New("no module proxy available")
+	// ErrNotFound is returned when no proxy in the list knows the module or version
+	ErrNotFound = errors.New("not found")
+)
+
+// Settings mirrors the go env variables that control proxy access
+type Settings struct {
+	GoProxy   string `json:"GOPROXY"`
+	GoNoProxy string `json:"GONOPROXY"`
+	GoPrivate string `json:"GOPRIVATE"`
+}
+
+// Info is the response of the @latest and @v/<version>.info endpoints
+type Info struct {
+	Version string    `json:"Version"`
+	Time    time.Time `json:"Time"`
+}
+
+type entry struct {
+	url string
+	// fall through to the next entry on any error, not only 404/410
+	anyError bool
+}
+
+// Client talks to module proxies using the GOPROXY protocol
+type Client struct {
+	client  *http.Client
+	entries []entry
+	noProxy string
+
+	// for clients from NewFromEnv, reads the settings on first use
+	load     func() Settings
+	loadOnce sync.Once
+
+	// opened module zips by module@version, shared by PackageName and
+	// LicenseFiles so each zip is downloaded once per session
+	zipsMu sync.Mutex
+	zips   map[string]*zipEntry
+}
+
+func New(s Settings) *Client {
+	c := &Client{
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+	c.configure(s)
+	return c
+}
+
+// NewFromEnv returns a client for the settings of go env. The go command
+// only runs once the client is first used, so commands that never contact
+// a proxy don't wait for it
+func NewFromEnv() *Client {
+	return &Client{
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		load: LoadSettings,
+	}
+}
+
+func (c *Client) configure(s Settings) {
+	c.noProxy = s.GoNoProxy
+	if c.noProxy == "" {
+		c.noProxy = s.GoPrivate
+	}
+	c.entries = parseProxyList(s.GoProxy)
+}
+
+func (c *Client) loadSettings() {
+	c.loadOnce.Do(func() {
+		if c.load != nil {
+			c.configure(c.load())
+		}
+	})
+}
+
+// reads proxy settings from go env, falling back to the process environment
+func LoadSettings() Settings {
+	s := Settings{
+		GoProxy:   os.Getenv("GOPROXY"),
+		GoNoProxy: os.Getenv("GONOPROXY"),
+		GoPrivate: os.Getenv("GOPRIVATE"),
+	}
+
+	cmd := exec.Command("go", "env", "-json", "GOPROXY", "GONOPROXY", "GOPRIVATE")
+	if output, err := cmd.Output(); err == nil {
+		json.Unmarshal(output, &s)
+	}
+
+	return s
+}
+
+// splits a GOPROXY value, remembering which separator follows each entry
+func parseProxyList(goproxy string) []entry {
+	if goproxy == "" {
+		goproxy = DefaultURL + ",direct"
+	}
+
+	var entries []entry
+	for goproxy != "" {
+		i := strings.IndexAny(goproxy, ",|")
+		if i < 0 {
+			entries = append(entries, entry{url: strings.TrimSuffix(strings.TrimSpace(goproxy), "/")})
+			break
+		}
+
+		if url := strings.TrimSpace(goproxy[:i]); url != "" {
+			entries = append(entries, entry{
+				url:      strings.TrimSuffix(url, "/"),
+				anyError: goproxy[i] == '|',
+			})
+		}
+		goproxy = goproxy[i+1:]
+	}
+
+	return entries
+}
+
+// reports whether modulePath is excluded from proxy lookups
+func (c *Client) IsPrivate(modulePath string) bool {
+	c.loadSettings()
+	return c.noProxy != "" && module.MatchPrefixPatterns(c.noProxy, modulePath)
+}
+
+// returns the tagged versions the proxy knows for modulePath. A module
+// without tags yields an empty list; an unknown module yields an error
+func (c *Client) List(modulePath string) ([]string, error) {
+	data, err := c.fetch(modulePath, "/@v/list")
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		// lines may carry extra fields after the version
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 {
+			versions = append(versions, fields[0])
+		}
+	}
+
+	return versions, nil
+}
+
+// returns the latest version of modulePath, which may be a pseudo-version
+func (c *Client) Latest(modulePath string) (*Info, error) {
+	data, err := c.fetch(modulePath, "/@latest")
+	if err != nil {
+		return nil, err
+	}
+	return parseInfo(data)
+}
+
+// returns the canonical version and commit time of modulePath@version
+func (c *Client) Info(modulePath, version string) (*Info, error) {
+	escaped, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %q: %w", version, err)
+	}
+
+	data, err := c.fetch(modulePath, "/@v/"+escaped+".info")
+	if err != nil {
+		return nil, err
+	}
+	return parseInfo(data)
+}
+
+// returns the go.mod file of modulePath@version
+func (c *Client) Mod(modulePath, version string) ([]byte, error) {
+	escaped, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %q: %w", version, err)
+	}
+
+	return c.fetch(modulePath, "/@v/"+escaped+".mod")
+}
+
+// returns every listed version newest first. Modules without tags fall back
+// to their @latest pseudo-version. Listed versions carry no timestamp, as
+// each costs a request; ReleaseTimes fetches them for the versions shown
+func (c *Client) Versions(modulePath string) ([]Info, error) {
+	versions, err := c.List(modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) == 0 {
+		latest, err := c.Latest(modulePath)
+		if err != nil {
+			return nil, err
+		}
+		return []Info{*latest}, nil
+	}
+
+	return sortedInfos(versions), nil
+}
+
+func sortedInfos(versions []string) []Info {
+	infos := make([]Info, len(versions))
+	for i, version := range versions {
+		infos[i] = Info{Version: version}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return semver.Compare(infos[i].Version, infos[j].Version) > 0
+	})
+	return infos
+}
+
+// returns when each of versions was published, fetching their .info 8 at a
+// time. Versions the proxy can't tell about are left out
+func (c *Client) ReleaseTimes(modulePath string, versions []string) map[string]time.Time {
+	times := make(map[string]time.Time, len(versions))
+	var mu sync.Mutex
+	sem := make(chan struct{}, 8)
+	var wg sync.WaitGroup
+
+	for _, version := range versions {
+		wg.Add(1)
+		go func(version string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if info, err := c.Info(modulePath, version); err == nil && !info.Time.IsZero() {
+				mu.Lock()
+				times[version] = info.Time
+				mu.Unlock()
+			}
+		}(version)
+	}
+	wg.Wait()
+
+	return times
+}
+
+// requests path (relative to the module) from each proxy in turn, following
+// the fallback rules of the go command
+func (c *Client) fetch(modulePath, path string) ([]byte, error) {
+	if c.IsPrivate(modulePath) {
+		return nil, ErrPrivate
+	}
+
+	escaped, err := module.EscapePath(modulePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid module path %q: %w", modulePath, err)
+	}
+
+	lastErr := ErrDisabled
+	for _, e := range c.entries {
+		if e.url == "off" || e.url == "direct" {
+			// we can't talk to VCS hosts, so direct ends the search like off
+			return nil, lastErr
+		}
+
+		data, status, err := c.get(e.url + "/" + escaped + path)
+		if err == nil {
+			return data, nil
+		}
+		lastErr = err
+
+		if !e.anyError && status != http.StatusNotFound && status != http.StatusGone {
+			return nil, err
+		}
+	}
+
+	return nil, lastErr
+}
+
+func (c *Client) get(url string) ([]byte, int, error) {
+	resp, err := c.client.Get(url)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return nil, resp.StatusCode, fmt.Errorf("%s: %w", url, ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, fmt.Errorf("unexpected status code from %s: %d", url, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+
+	return data, resp.StatusCode, nil
+}
+
+func parseInfo(data []byte) (*Info, error) {
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse version info: %w", err)
+	}
+	return &info, nil
+}
@@ -0,0 +1,322 @@
+package proxy
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serves a tiny module proxy for github.com/BurntSushi/toml and an untagged module
+func newFakeProxy(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github.com/!burnt!sushi/toml/@v/list":
+			fmt.Fprint(w, "v1.2.0\nv1.3.2\nv1.10.0\n\n")
+		case "/github.com/!burnt!sushi/toml/@latest",
+			"/github.com/!burnt!sushi/toml/@v/v1.10.0.info":
+			fmt.Fprint(w, `{"Version":"v1.10.0","Time":"2024-03-01T10:00:00Z"}`)
+		case "/github.com/!burnt!sushi/toml/@v/v1.3.2.info":
+			fmt.Fprint(w, `{"Version":"v1.3.2","Time":"2023-06-08T10:00:00Z"}`)
+		case "/github.com/!burnt!sushi/toml/@v/v1.2.0.info":
+			fmt.Fprint(w, `{"Version":"v1.2.0","Time":"2022-06-08T10:00:00Z"}`)
+		case "/github.com/!burnt!sushi/toml/@v/v1.3.2.mod":
+			fmt.Fprint(w, "module github.com/BurntSushi/toml\n\ngo 1.16\n")
+		case "/github.com/test/untagged/@v/list":
+		case "/github.com/test/untagged/@latest":
+			fmt.Fprint(w, `{"Version":"v0.0.0-20240101000000-abcdefabcdef","Time":"2024-01-01T00:00:00Z"}`)
+		case "/github.com/test/broken/@v/list":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestParseProxyList(t *testing.T) {
+	entries := parseProxyList("https://a.example.com/,https://b.example.com|direct")
+	assert.Equal(t, []entry{
+		{url: "https://a.example.com"},
+		{url: "https://b.example.com", anyError: true},
+		{url: "direct"},
+	}, entries)
+
+	entries = parseProxyList("")
+	assert.Equal(t, []entry{{url: DefaultURL}, {url: "direct"}}, entries)
+}
+
+func TestList(t *testing.T) {
+	server := newFakeProxy(t)
+	c := New(Settings{GoProxy: server.URL})
+
+	versions, err := c.List("github.com/BurntSushi/toml")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.2.0", "v1.3.2", "v1.10.0"}, versions)
+
+	versions, err = c.List("github.com/test/untagged")
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+
+	_, err = c.List("github.com/test/missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLatestInfoMod(t *testing.T) {
+	server := newFakeProxy(t)
+	c := New(Settings{GoProxy: server.URL})
+
+	latest, err := c.Latest("github.com/BurntSushi/toml")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.10.0", latest.Version)
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), latest.Time)
+
+	info, err := c.Info("github.com/BurntSushi/toml", "v1.3.2")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.3.2", info.Version)
+
+	mod, err := c.Mod("github.com/BurntSushi/toml", "v1.3.2")
+	require.NoError(t, err)
+	assert.Contains(t, string(mod), "module github.com/BurntSushi/toml")
+}
+
+func TestVersions(t *testing.T) {
+	server := newFakeProxy(t)
+	c := New(Settings{GoProxy: server.URL})
+
+	versions, err := c.Versions("github.com/BurntSushi/toml")
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+
+	// semver order, not lexical
+	assert.Equal(t, "v1.10.0", versions[0].Version)
+	assert.Equal(t, "v1.3.2", versions[1].Version)
+	assert.Equal(t, "v1.2.0", versions[2].Version)
+	// timestamps are only fetched on request
+	assert.True(t, versions[2].Time.IsZero())
+
+	times := c.ReleaseTimes("github.com/BurntSushi/toml", []string{"v1.2.0", "v9.9.9"})
+	assert.Equal(t, 2022, times["v1.2.0"].Year())
+	assert.NotContains(t, times, "v9.9.9")
+
+	versions, err = c.Versions("github.com/test/untagged")
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, "v0.0.0-20240101000000-abcdefabcdef", versions[0].Version)
+}
+
+func TestFallback(t *testing.T) {
+	server := newFakeProxy(t)
+	empty := httptest.NewServer(http.NotFoundHandler())
+	defer empty.Close()
+
+	// comma falls through on 404
+	c := New(Settings{GoProxy: empty.URL + "," + server.URL})
+	_, err := c.List("github.com/BurntSushi/toml")
+	assert.NoError(t, err)
+
+	// comma stops on other errors
+	c = New(Settings{GoProxy: server.URL + "," + empty.URL})
+	_, err = c.List("github.com/test/broken")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrNotFound))
+
+	// pipe falls through on any error
+	c = New(Settings{GoProxy: server.URL + "|" + empty.URL})
+	_, err = c.List("github.com/test/broken")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestNewFromEnvLoadsOnFirstUse(t *testing.T) {
+	server := newFakeProxy(t)
+
+	loads := 0
+	c := NewFromEnv()
+	c.load = func() Settings {
+		loads++
+		return Settings{GoProxy: server.URL}
+	}
+	assert.Zero(t, loads)
+
+	_, err := c.List("github.com/BurntSushi/toml")
+	require.NoError(t, err)
+	_, err = c.Latest("github.com/BurntSushi/toml")
+	require.NoError(t, err)
+	assert.Equal(t, 1, loads)
+}
+
+func TestPrivateAndDisabled(t *testing.T) {
+	server := newFakeProxy(t)
+
+	c := New(Settings{GoProxy: server.URL, GoPrivate: "github.com/BurntSushi/*"})
+	assert.True(t, c.IsPrivate("github.com/BurntSushi/toml"))
+	_, err := c.List("github.com/BurntSushi/toml")
+	assert.ErrorIs(t, err, ErrPrivate)
+
+	// GONOPROXY takes precedence over GOPRIVATE
+	c = New(Settings{GoProxy: server.URL, GoNoProxy: "example.com", GoPrivate: "github.com"})
+	assert.False(t, c.IsPrivate("github.com/BurntSushi/toml"))
+
+	c = New(Settings{GoProxy: "off"})
+	_, err = c.List("github.com/BurntSushi/toml")
+	assert.ErrorIs(t, err, ErrDisabled)
+
+	c = New(Settings{GoProxy: "direct"})
+	_, err = c.List("github.com/BurntSushi/toml")
+	assert.ErrorIs(t, err, ErrDisabled)
+}
+
+func TestVersionHistory(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch r.URL.Path {
+		case "/github.com/test/pkg/@v/list":
+			fmt.Fprint(w, "v1.0.0\nv1.1.0\nv1.2.0\n")
+		case "/github.com/test/pkg/@latest":
+			fmt.Fprint(w, `{"Version":"v1.2.1-0.20240501000000-abcdefabcdef","Time":"2024-05-01T00:00:00Z"}`)
+		case "/github.com/test/pkg/@v/v1.2.0.mod":
+			fmt.Fprint(w, "module github.com/test/pkg\n\nretract (\n\t// Published with a broken API.\n\t[v1.0.0, v1.1.0]\n)\n")
+		default:
+			if strings.HasSuffix(r.URL.Path, ".info") {
+				fmt.Fprint(w, `{"Time":"2023-01-01T00:00:00Z"}`)
+				return
+			}
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := New(Settings{GoProxy: server.URL})
+
+	versions, err := c.VersionHistory("github.com/test/pkg")
+	require.NoError(t, err)
+	require.Len(t, versions, 4)
+
+	assert.Equal(t, "v1.2.1-0.20240501000000-abcdefabcdef", versions[0].Version)
+	assert.True(t, versions[0].Pseudo)
+	assert.False(t, versions[0].Retracted)
+
+	assert.Equal(t, "v1.2.0", versions[1].Version)
+	assert.False(t, versions[1].Retracted)
+
+	assert.Equal(t, "v1.1.0", versions[2].Version)
+	assert.True(t, versions[2].Retracted)
+	assert.Equal(t, "Published with a broken API.", versions[2].Rationale)
+	assert.True(t, versions[3].Retracted)
+
+	// @latest is asked once and no .info is fetched
+	assert.ElementsMatch(t, []string{
+		"/github.com/test/pkg/@v/list",
+		"/github.com/test/pkg/@latest",
+		"/github.com/test/pkg/@v/v1.2.0.mod",
+	}, requests)
+}
+
+// serves example.com/tools@v1.0.0 as a zip of the given files, counting
+// the zip downloads
+func newZipProxy(t *testing.T, files map[string]string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create("example.com/tools@v1.0.0/" + name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/example.com/tools/@v/v1.0.0.zip" {
+			downloads.Add(1)
+			w.Write(buf.Bytes())
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &downloads
+}
+
+func TestPackageName(t *testing.T) {
+	server, _ := newZipProxy(t, map[string]string{
+		"go.mod":              "module example.com/tools\n",
+		"tools.go":            "package tools\n",
+		"cmd/gen/doc.go":      "// Gen generates code.\npackage main\n",
+		"cmd/gen/gen_test.go": "package main_test\n",
+		"cmd/gen/sub/sub.go":  "package sub\n",
+	})
+
+	c := New(Settings{GoProxy: server.URL})
+
+	name, err := c.PackageName("example.com/tools", "v1.0.0", "example.com/tools/cmd/gen")
+	require.NoError(t, err)
+	assert.Equal(t, "main", name)
+
+	name, err = c.PackageName("example.com/tools", "v1.0.0", "example.com/tools")
+	require.NoError(t, err)
+	assert.Equal(t, "tools", name)
+
+	_, err = c.PackageName("example.com/tools", "v1.0.0", "example.com/tools/missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = c.PackageName("example.com/tools", "v1.0.0", "example.com/other")
+	assert.Error(t, err)
+}
+
+func TestLicenseFiles(t *testing.T) {
+	server, _ := newZipProxy(t, map[string]string{
+		"go.mod":              "module example.com/tools\n",
+		"LICENSE":             "MIT License",
+		"COPYING.md":          "GPL",
+		"LICENSE-APACHE":      "Apache License",
+		"licenses.go":         "package tools\n",
+		"vendor/x/LICENSE":    "nested",
+		"cmd/gen/LICENSE.txt": "nested",
+	})
+
+	files, err := New(Settings{GoProxy: server.URL}).LicenseFiles("example.com/tools", "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"LICENSE":        "MIT License",
+		"COPYING.md":     "GPL",
+		"LICENSE-APACHE": "Apache License",
+	}, files)
+}
+
+func TestZipDownloadedOnce(t *testing.T) {
+	server, downloads := newZipProxy(t, map[string]string{
+		"go.mod":   "module example.com/tools\n",
+		"LICENSE":  "MIT License",
+		"tools.go": "package tools\n",
+	})
+
+	c := New(Settings{GoProxy: server.URL})
+
+	_, err := c.PackageName("example.com/tools", "v1.0.0", "example.com/tools")
+	require.NoError(t, err)
+	_, err = c.LicenseFiles("example.com/tools", "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), downloads.Load())
+
+	// failures are not kept
+	_, err = c.LicenseFiles("example.com/tools", "v9.9.9")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotContains(t, c.zips, "example.com/tools@v9.9.9")
+}
@@ -0,0 +1,98 @@
+package proxy
+
+import (
+	"sort"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Version is a module version annotated for display in a version picker
+type Version struct {
+	Version   string    `json:"version"`
+	Time      time.Time `json:"time"`
+	Pseudo    bool      `json:"pseudo,omitempty"`
+	Retracted bool      `json:"retracted,omitempty"`
+	Rationale string    `json:"rationale,omitempty"`
+}
+
+// returns all versions of modulePath newest first, including the
+// pseudo-version @latest resolves to and retractions declared in the
+// go.mod of the latest release. Like Versions, only the @latest version
+// carries a timestamp
+func (c *Client) VersionHistory(modulePath string) ([]Version, error) {
+	list, err := c.List(modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := sortedInfos(list)
+	latest, err := c.Latest(modulePath)
+	switch {
+	case err != nil && len(infos) == 0:
+		return nil, err
+	case err == nil:
+		if i := indexVersion(infos, latest.Version); i >= 0 {
+			infos[i].Time = latest.Time
+			break
+		}
+		infos = append(infos, *latest)
+		sort.Slice(infos, func(i, j int) bool {
+			return semver.Compare(infos[i].Version, infos[j].Version) > 0
+		})
+	}
+
+	var retractions []*modfile.Retract
+	if latest := latestRelease(infos); latest != "" {
+		if data, err := c.Mod(modulePath, latest); err == nil {
+			if mf, err := modfile.ParseLax("go.mod", data, nil); err == nil {
+				retractions = mf.Retract
+			}
+		}
+	}
+
+	versions := make([]Version, 0, len(infos))
+	for _, info := range infos {
+		v := Version{
+			Version: info.Version,
+			Time:    info.Time,
+			Pseudo:  module.IsPseudoVersion(info.Version),
+		}
+
+		for _, r := range retractions {
+			if semver.Compare(v.Version, r.Low) >= 0 && semver.Compare(v.Version, r.High) <= 0 {
+				v.Retracted = true
+				v.Rationale = r.Rationale
+				break
+			}
+		}
+
+		versions = append(versions, v)
+	}
+
+	return versions, nil
+}
+
+func indexVersion(infos []Info, version string) int {
+	for i, info := range infos {
+		if info.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// like the go command, prefers tagged releases when looking for retractions
+func latestRelease(infos []Info) string {
+	for _, info := range infos {
+		if !module.IsPseudoVersion(info.Version) {
+			return info.Version
+		}
+	}
+	if len(infos) > 0 {
+		return infos[0].Version
+	}
+	return ""
+}
@@ -0,0 +1,161 @@
+package proxy
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"path"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+)
+
+// returns the zip archive of modulePath@version
+func (c *Client) Zip(modulePath, version string) ([]byte, error) {
+	escaped, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %q: %w", version, err)
+	}
+
+	return c.fetch(modulePath, "/@v/"+escaped+".zip")
+}
+
+// returns the package name declared by the non-test Go files of importPath
+// in modulePath@version, e.g. "main" for commands
+func (c *Client) PackageName(modulePath, version, importPath string) (string, error) {
+	if importPath != modulePath && !strings.HasPrefix(importPath, modulePath+"/") {
+		return "", fmt.Errorf("%s is not part of module %s", importPath, modulePath)
+	}
+
+	zr, err := c.openZip(modulePath, version)
+	if err != nil {
+		return "", err
+	}
+
+	// files are stored as module@version/dir/file.go
+	dir := modulePath + "@" + version + strings.TrimPrefix(importPath, modulePath)
+	for _, f := range zr.File {
+		name := f.Name
+		if path.Dir(name) != dir || path.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		if pkg, err := packageClause(f); err == nil && pkg != "documentation" {
+			return pkg, nil
+		}
+	}
+
+	return "", fmt.Errorf("no Go files for %s in %s@%s: %w", importPath, modulePath, version, ErrNotFound)
+}
+
+// returns the license files at the root of modulePath@version, such as
+// LICENSE, LICENSE.md or COPYING, keyed by file name
+func (c *Client) LicenseFiles(modulePath, version string) (map[string]string, error) {
+	zr, err := c.openZip(modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	root := modulePath + "@" + version
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		if path.Dir(f.Name) != root || !isLicenseFile(path.Base(f.Name)) {
+			continue
+		}
+
+		data, err := readZipFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		files[path.Base(f.Name)] = string(data)
+	}
+
+	return files, nil
+}
+
+func isLicenseFile(name string) bool {
+	base := strings.ToUpper(strings.TrimSuffix(name, path.Ext(name)))
+	for _, prefix := range []string{"LICENSE", "LICENCE", "COPYING", "UNLICENSE"} {
+		if base == prefix || strings.HasPrefix(base, prefix+"-") || strings.HasPrefix(base, prefix+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+type zipEntry struct {
+	once sync.Once
+	zr   *zip.Reader
+	err  error
+}
+
+// returns the zip of modulePath@version, downloading it only on first use.
+// Concurrent callers wait for the same download; failed downloads are
+// forgotten so a later call can retry
+func (c *Client) openZip(modulePath, version string) (*zip.Reader, error) {
+	key := modulePath + "@" + version
+
+	c.zipsMu.Lock()
+	if c.zips == nil {
+		c.zips = make(map[string]*zipEntry)
+	}
+	e, ok := c.zips[key]
+	if !ok {
+		e = &zipEntry{}
+		c.zips[key] = e
+	}
+	c.zipsMu.Unlock()
+
+	e.once.Do(func() {
+		e.zr, e.err = c.downloadZip(modulePath, version)
+	})
+
+	if e.err != nil {
+		c.zipsMu.Lock()
+		if c.zips[key] == e {
+			delete(c.zips, key)
+		}
+		c.zipsMu.Unlock()
+	}
+	return e.zr, e.err
+}
+
+func (c *Client) downloadZip(modulePath, version string) (*zip.Reader, error) {
+	data, err := c.Zip(modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open module zip: %w", err)
+	}
+	return zr, nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
+
+func packageClause(f *zip.File) (string, error) {
+	src, err := readZipFile(f)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), f.Name, src, parser.PackageClauseOnly)
+	if err != nil {
+		return "", err
+	}
+	return file.Name.Name, nil
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,6 +13,10 @@ import (
 	"github.com/PuerkitoBio/goquery"
 )
 
+const maxReadmeLines = 60
+
+var numberRe = regexp.MustCompile(`[0-9][0-9,]*`)
+
 type Scraper struct {
 	client     *http.Client
 	maxRetries int
@@ -173,11 +179,19 @@ func (s *Scraper) parsePackage(sel *goquery.Selection) *cache.Package {
 		version = strings.TrimPrefix(version, "v")
 	}
 
+	license := collapseSpace(sel.Find("[data-test-id='snippet-license']").First().Text())
+	importedBy := parseCount(sel.Find("[data-test-id='snippet-importedby']").First().Text())
+	published := parsePublished(collapseSpace(sel.Find("[data-test-id='snippet-published'] strong").First().Text()))
+
 	return &cache.Package{
 		Name:        name,
 		ImportPath:  importPath,
 		Description: description,
 		Version:     version,
+		License:     license,
+		ImportedBy:  importedBy,
+		Published:   published,
+		Command:     hasChip(sel, "command"),
 	}
 }
 
@@ -199,24 +213,114 @@ func (s *Scraper) FetchPackageDetails(importPath string) (*cache.Package, error)
 		return nil, fmt.Errorf("failed to parse package page: %w", err)
 	}
 
-	name := doc.Find("h1").First().Text()
+	name := strings.TrimSpace(doc.Find("h1").First().Text())
 	if name == "" {
 		parts := strings.Split(importPath, "/")
 		name = parts[len(parts)-1]
 	}
 
-	description := doc.Find(".Documentation-overview p").First().Text()
+	description := strings.TrimSpace(doc.Find(".Documentation-overview p").First().Text())
 	if description == "" {
 		description = doc.Find("meta[name='description']").AttrOr("content", "")
 	}
 
-	version := doc.Find(".DetailsHeader-version").First().Text()
-	version = strings.TrimSpace(strings.TrimPrefix(version, "v"))
+	version := headerValue(doc, "UnitHeader-version")
+	if version == "" {
+		version = strings.TrimSpace(doc.Find(".DetailsHeader-version").First().Text())
+	}
+	version = strings.TrimPrefix(version, "v")
+
+	importedBy := parseCount(headerValue(doc, "UnitHeader-importedby"))
+
+	repository := strings.TrimSpace(doc.Find(".UnitMeta-repo a").First().AttrOr("href", ""))
 
 	return &cache.Package{
 		Name:        name,
 		ImportPath:  importPath,
 		Description: description,
 		Version:     version,
+		License:     headerValue(doc, "UnitHeader-licenses"),
+		Published:   parsePublished(headerValue(doc, "UnitHeader-commitTime")),
+		ImportedBy:  importedBy,
+		Repository:  repository,
+		Readme:      parseReadme(doc.Find(".Overview-readmeContent, .UnitReadme-content").First()),
+		Command:     hasChip(doc.Find(".UnitHeader-titleHeading, .UnitHeader-title").First(), "command"),
 	}, nil
 }
+
+// reports whether sel carries a label chip such as "command" or "standard library"
+func hasChip(sel *goquery.Selection, label string) bool {
+	found := false
+	sel.Find(".go-Chip, .SearchSnippet-header-chip").EachWithBreak(func(i int, chip *goquery.Selection) bool {
+		found = strings.EqualFold(collapseSpace(chip.Text()), label)
+		return !found
+	})
+	return found
+}
+
+// reads a "Label: value" item of the package header
+func headerValue(doc *goquery.Document, testID string) string {
+	text := strings.TrimSpace(doc.Find("[data-test-id='" + testID + "']").First().Text())
+	if _, value, ok := strings.Cut(text, ":"); ok {
+		text = value
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// reads the first number of text, such as "Imported by 1,234"
+func parseCount(text string) int {
+	n, _ := strconv.Atoi(strings.ReplaceAll(numberRe.FindString(text), ",", ""))
+	return n
+}
+
+// normalizes pkg.go.dev dates ("Jan 2, 2006") to 2006-01-02
+func parsePublished(text string) string {
+	if t, err := time.Parse("Jan 2, 2006", text); err == nil {
+		return t.Format("2006-01-02")
+	}
+	return text
+}
+
+// flattens the rendered README into plain text with markdown-style headings,
+// keeping only the first maxReadmeLines lines
+func parseReadme(sel *goquery.Selection) string {
+	var lines []string
+
+	sel.Find("h1, h2, h3, h4, p, pre, li").Each(func(i int, block *goquery.Selection) {
+		// text of nested blocks is already part of the enclosing item
+		if block.ParentsFiltered("li, pre").Length() > 0 {
+			return
+		}
+
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+
+		switch goquery.NodeName(block) {
+		case "pre":
+			for _, line := range strings.Split(strings.TrimRight(block.Text(), "\n"), "\n") {
+				lines = append(lines, "    "+line)
+			}
+		case "li":
+			// list items are kept together
+			if len(lines) > 1 && strings.HasPrefix(lines[len(lines)-2], "• ") {
+				lines = lines[:len(lines)-1]
+			}
+			lines = append(lines, "• "+collapseSpace(block.Text()))
+		case "p":
+			lines = append(lines, collapseSpace(block.Text()))
+		default:
+			lines = append(lines, "# "+collapseSpace(block.Text()))
+		}
+	})
+
+	if len(lines) > maxReadmeLines {
+		lines = append(lines[:maxReadmeLines], "…")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func collapseSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -65,6 +65,79 @@ func TestParseResults(t *testing.T) {
 	assert.Equal(t, "Go configuration with fangs", results[1].Description)
 }
 
+func TestParseCommandChip(t *testing.T) {
+	html := `
+	<div class="SearchSnippet">
+		<div class="SearchSnippet-headerContainer">
+			<h2><a href="/golang.org/x/tools/cmd/stringer">stringer</a></h2>
+			<span class="go-Chip go-Chip--inverted">command</span>
+		</div>
+		<p class="SearchSnippet-synopsis">Stringer is a tool to automate the creation of methods</p>
+	</div>
+	<div class="SearchSnippet">
+		<h2><a href="/golang.org/x/tools/go/packages">packages</a></h2>
+	</div>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	require.NoError(t, err)
+
+	results, err := New().parseResults(doc)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "stringer", results[0].Name)
+	assert.True(t, results[0].Command)
+	assert.False(t, results[1].Command)
+}
+
+func TestParseSnippetLicense(t *testing.T) {
+	html := `
+	<div class="SearchSnippet">
+		<h2><a href="/github.com/spf13/cobra">cobra</a></h2>
+		<div class="SearchSnippet-infoLabel">
+			<span data-test-id="snippet-license">
+				<a href="/github.com/spf13/cobra?tab=licenses">Apache-2.0</a>
+			</span>
+		</div>
+	</div>
+	<div class="SearchSnippet">
+		<h2><a href="/github.com/spf13/viper">viper</a></h2>
+	</div>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	require.NoError(t, err)
+
+	results, err := New().parseResults(doc)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "Apache-2.0", results[0].License)
+	assert.Empty(t, results[1].License)
+}
+
+func TestParseSnippetMetadata(t *testing.T) {
+	html := `
+	<div class="SearchSnippet">
+		<h2><a href="/github.com/spf13/cobra">cobra</a></h2>
+		<div class="SearchSnippet-infoLabel">
+			<a href="/github.com/spf13/cobra?tab=importedby" data-test-id="snippet-importedby">
+				<span class="go-textSubtle">Imported by </span><strong>184,212</strong>
+			</a>
+			<span class="go-textSubtle" data-test-id="snippet-published"><strong>Sep 3, 2024</strong></span>
+		</div>
+	</div>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	require.NoError(t, err)
+
+	results, err := New().parseResults(doc)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	assert.Equal(t, 184212, results[0].ImportedBy)
+	assert.Equal(t, "2024-09-03", results[0].Published)
+}
+
 func TestParsePackage(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -235,3 +308,58 @@ func TestFetchPackageDetails(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, pkg)
 }
+
+func TestFetchPackageDetailsUnitPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`
+			<html>
+				<h1 class="UnitHeader-titleHeading">cobra</h1>
+				<span data-test-id="UnitHeader-version"><a>Version: v1.8.0</a></span>
+				<span data-test-id="UnitHeader-commitTime">Published: Dec 30, 2023</span>
+				<span data-test-id="UnitHeader-licenses">License: <a>Apache-2.0</a></span>
+				<span data-test-id="UnitHeader-importedby"><a>Imported by: 184,234</a></span>
+				<div class="UnitMeta-repo"><a href="https://github.com/spf13/cobra">github.com/spf13/cobra</a></div>
+				<div class="Overview-readmeContent">
+					<h2>Overview</h2>
+					<p>Cobra is a library   for creating
+					CLI applications.</p>
+					<ul><li><p>Easy subcommands</p></li><li>Nested flags</li></ul>
+					<pre>go get -u github.com/spf13/cobra</pre>
+				</div>
+			</html>
+		`))
+	}))
+	defer server.Close()
+
+	s := &Scraper{
+		client:     &http.Client{Timeout: 5 * time.Second},
+		maxRetries: 1,
+		baseURL:    server.URL,
+	}
+
+	pkg, err := s.FetchPackageDetails("github.com/spf13/cobra")
+	require.NoError(t, err)
+	assert.Equal(t, "cobra", pkg.Name)
+	assert.Equal(t, "1.8.0", pkg.Version)
+	assert.Equal(t, "2023-12-30", pkg.Published)
+	assert.Equal(t, "Apache-2.0", pkg.License)
+	assert.Equal(t, 184234, pkg.ImportedBy)
+	assert.Equal(t, "https://github.com/spf13/cobra", pkg.Repository)
+	assert.Equal(t, "# Overview\n\nCobra is a library for creating CLI applications.\n\n• Easy subcommands\n• Nested flags\n\n    go get -u github.com/spf13/cobra", pkg.Readme)
+}
+
+func TestParseReadmeTruncates(t *testing.T) {
+	var html strings.Builder
+	html.WriteString("<div>")
+	for i := 0; i < maxReadmeLines; i++ {
+		html.WriteString("<p>line</p>")
+	}
+	html.WriteString("</div>")
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html.String()))
+	require.NoError(t, err)
+
+	lines := strings.Split(parseReadme(doc.Find("div")), "\n")
+	assert.Len(t, lines, maxReadmeLines+1)
+	assert.Equal(t, "…", lines[maxReadmeLines])
+}
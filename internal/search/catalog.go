@@ -0,0 +1,128 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+)
+
+// Catalog searches a static JSON list of packages, e.g. internal modules
+// that pkg.go.dev will never index
+type Catalog struct {
+	packages []cache.Package
+}
+
+// loads a catalog file containing a JSON array of packages
+func NewCatalog(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog: %w", err)
+	}
+
+	var packages []cache.Package
+	if err := json.Unmarshal(data, &packages); err != nil {
+		return nil, fmt.Errorf("failed to parse catalog %s: %w", path, err)
+	}
+
+	return &Catalog{packages: packages}, nil
+}
+
+func (c *Catalog) Search(query string) ([]cache.Package, error) {
+	return filterPackages(c.packages, query), nil
+}
+
+func (c *Catalog) FetchPackageDetails(importPath string) (*cache.Package, error) {
+	return findPackage(c.packages, importPath)
+}
+
+// HTTPCatalog queries a company catalog service. The service answers
+// GET <url>?q=<query> with a JSON array of packages
+type HTTPCatalog struct {
+	client  *http.Client
+	baseURL string
+}
+
+func NewHTTPCatalog(baseURL string) *HTTPCatalog {
+	return &HTTPCatalog{
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		baseURL: baseURL,
+	}
+}
+
+func (h *HTTPCatalog) Search(query string) ([]cache.Package, error) {
+	if query == "" {
+		return []cache.Package{}, nil
+	}
+
+	searchURL := fmt.Sprintf("%s?q=%s", h.baseURL, url.QueryEscape(query))
+
+	resp, err := h.client.Get(searchURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query catalog: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var packages []cache.Package
+	if err := json.NewDecoder(resp.Body).Decode(&packages); err != nil {
+		return nil, fmt.Errorf("failed to parse catalog response: %w", err)
+	}
+
+	return packages, nil
+}
+
+func (h *HTTPCatalog) FetchPackageDetails(importPath string) (*cache.Package, error) {
+	packages, err := h.Search(importPath)
+	if err != nil {
+		return nil, err
+	}
+	return findPackage(packages, importPath)
+}
+
+// keeps packages whose name, import path or description contain every query word
+func filterPackages(packages []cache.Package, query string) []cache.Package {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return []cache.Package{}
+	}
+
+	matches := []cache.Package{}
+	for _, pkg := range packages {
+		haystack := strings.ToLower(pkg.Name + " " + pkg.ImportPath + " " + pkg.Description)
+
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(haystack, word) {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			matches = append(matches, pkg)
+		}
+	}
+
+	return matches
+}
+
+func findPackage(packages []cache.Package, importPath string) (*cache.Package, error) {
+	for _, pkg := range packages {
+		if pkg.ImportPath == importPath {
+			found := pkg
+			return &found, nil
+		}
+	}
+	return nil, fmt.Errorf("package not found: %s", importPath)
+}
@@ -0,0 +1,127 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+)
+
+// Chain queries several searchers and merges their results in order
+type Chain struct {
+	searchers []Searcher
+}
+
+func NewChain(searchers ...Searcher) *Chain {
+	return &Chain{searchers: searchers}
+}
+
+// runs all searchers concurrently. Results keep provider order and are
+// de-duplicated by import path; it only fails if every provider fails
+func (c *Chain) Search(query string) ([]cache.Package, error) {
+	results := make([][]cache.Package, len(c.searchers))
+	errs := make([]error, len(c.searchers))
+
+	var wg sync.WaitGroup
+	for i, s := range c.searchers {
+		wg.Add(1)
+		go func(i int, s Searcher) {
+			defer wg.Done()
+			results[i], errs[i] = s.Search(query)
+		}(i, s)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	packages := []cache.Package{}
+	failed := 0
+
+	for i := range c.searchers {
+		if errs[i] != nil {
+			failed++
+			continue
+		}
+		for _, pkg := range results[i] {
+			if seen[pkg.ImportPath] {
+				continue
+			}
+			seen[pkg.ImportPath] = true
+			packages = append(packages, pkg)
+		}
+	}
+
+	if len(c.searchers) > 0 && failed == len(c.searchers) {
+		return nil, fmt.Errorf("all search providers failed: %w", errors.Join(errs...))
+	}
+
+	return packages, nil
+}
+
+// returns details from the first provider that knows the package
+func (c *Chain) FetchPackageDetails(importPath string) (*cache.Package, error) {
+	var errs []error
+	for _, s := range c.searchers {
+		pkg, err := s.FetchPackageDetails(importPath)
+		if err == nil {
+			return pkg, nil
+		}
+		errs = append(errs, err)
+	}
+
+	return nil, fmt.Errorf("package not found: %s: %w", importPath, errors.Join(errs...))
+}
+
+// Fallback answers from its fallback searcher when the primary one fails,
+// e.g. the local module cache when pkg.go.dev is unreachable
+type Fallback struct {
+	primary  Searcher
+	fallback Searcher
+}
+
+func NewFallback(primary, fallback Searcher) *Fallback {
+	return &Fallback{primary: primary, fallback: fallback}
+}
+
+func (f *Fallback) Search(query string) ([]cache.Package, error) {
+	packages, _, err := f.SearchSource(query)
+	return packages, err
+}
+
+// like Search, also reporting whether the fallback searcher answered
+func (f *Fallback) SearchSource(query string) ([]cache.Package, bool, error) {
+	packages, err := f.primary.Search(query)
+	if err == nil {
+		return packages, false, nil
+	}
+
+	packages, fallbackErr := f.fallback.Search(query)
+	if fallbackErr != nil {
+		return nil, false, errors.Join(err, fallbackErr)
+	}
+	return packages, true, nil
+}
+
+// runs s and reports whether the answer came from a fallback searcher.
+// Those answers are stand-ins for an unreachable provider and are not worth
+// caching
+func SearchSource(s Searcher, query string) ([]cache.Package, bool, error) {
+	if f, ok := s.(*Fallback); ok {
+		return f.SearchSource(query)
+	}
+	packages, err := s.Search(query)
+	return packages, false, err
+}
+
+func (f *Fallback) FetchPackageDetails(importPath string) (*cache.Package, error) {
+	pkg, err := f.primary.FetchPackageDetails(importPath)
+	if err == nil {
+		return pkg, nil
+	}
+
+	pkg, fallbackErr := f.fallback.FetchPackageDetails(importPath)
+	if fallbackErr != nil {
+		return nil, errors.Join(err, fallbackErr)
+	}
+	return pkg, nil
+}
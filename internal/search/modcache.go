@@ -0,0 +1,252 @@
+package search
+
+import (
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+)
+
+// ModCache searches the local module cache, so it works without network
+// access. The index is built lazily on the first search
+type ModCache struct {
+	root string
+
+	once  sync.Once
+	index []cache.Package
+}
+
+func NewModCache(root string) *ModCache {
+	return &ModCache{root: root}
+}
+
+func (m *ModCache) Search(query string) ([]cache.Package, error) {
+	m.once.Do(m.buildIndex)
+	return filterPackages(m.index, query), nil
+}
+
+func (m *ModCache) FetchPackageDetails(importPath string) (*cache.Package, error) {
+	m.once.Do(m.buildIndex)
+	return findPackage(m.index, importPath)
+}
+
+func (m *ModCache) buildIndex() {
+	versions := make(map[string][]string)  // module path -> versions seen
+	extracted := make(map[string][]string) // module path -> extracted versions
+
+	m.scanDownloadCache(versions)
+	m.scanExtracted(extracted)
+
+	for modulePath, vs := range extracted {
+		versions[modulePath] = append(versions[modulePath], vs...)
+	}
+
+	var index []cache.Package
+	for modulePath, vs := range versions {
+		latest := latestVersion(vs)
+
+		if dirVersion := latestVersion(extracted[modulePath]); dirVersion != "" {
+			if pkgs := m.indexModule(modulePath, dirVersion); len(pkgs) > 0 {
+				index = append(index, pkgs...)
+				continue
+			}
+		}
+
+		index = append(index, cache.Package{
+			Name:       path.Base(modulePath),
+			ImportPath: modulePath,
+			Version:    latest,
+			ModulePath: modulePath,
+			InModCache: true,
+		})
+	}
+
+	sort.Slice(index, func(i, j int) bool {
+		return index[i].ImportPath < index[j].ImportPath
+	})
+
+	m.index = index
+}
+
+// collects module versions from cache/download/<module>/@v/*.{info,mod,zip}
+func (m *ModCache) scanDownloadCache(versions map[string][]string) {
+	downloadDir := filepath.Join(m.root, "cache", "download")
+
+	filepath.WalkDir(downloadDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if p == filepath.Join(downloadDir, "sumdb") {
+			return filepath.SkipDir
+		}
+		if d.Name() != "@v" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(downloadDir, filepath.Dir(p))
+		if err != nil {
+			return filepath.SkipDir
+		}
+		modulePath, err := module.UnescapePath(filepath.ToSlash(rel))
+		if err != nil {
+			return filepath.SkipDir
+		}
+
+		entries, _ := os.ReadDir(p)
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if ext != ".info" && ext != ".mod" && ext != ".zip" {
+				continue
+			}
+			if v, err := module.UnescapeVersion(strings.TrimSuffix(entry.Name(), ext)); err == nil {
+				versions[modulePath] = append(versions[modulePath], v)
+			}
+		}
+
+		return filepath.SkipDir
+	})
+}
+
+// collects extracted <module>@<version> directories
+func (m *ModCache) scanExtracted(extracted map[string][]string) {
+	filepath.WalkDir(m.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if p == filepath.Join(m.root, "cache") {
+			return filepath.SkipDir
+		}
+
+		escapedPath, escapedVersion, ok := strings.Cut(d.Name(), "@")
+		if !ok {
+			return nil
+		}
+
+		rel, err := filepath.Rel(m.root, filepath.Join(filepath.Dir(p), escapedPath))
+		if err != nil {
+			return filepath.SkipDir
+		}
+		modulePath, err := module.UnescapePath(filepath.ToSlash(rel))
+		if err != nil {
+			return filepath.SkipDir
+		}
+		version, err := module.UnescapeVersion(escapedVersion)
+		if err != nil {
+			return filepath.SkipDir
+		}
+
+		extracted[modulePath] = append(extracted[modulePath], version)
+		return filepath.SkipDir
+	})
+}
+
+// returns one entry per importable package of modulePath@version
+func (m *ModCache) indexModule(modulePath, version string) []cache.Package {
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return nil
+	}
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil
+	}
+
+	moduleDir := filepath.Join(m.root, filepath.FromSlash(escapedPath)+"@"+escapedVersion)
+
+	var pkgs []cache.Package
+	filepath.WalkDir(moduleDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+
+		if p != moduleDir {
+			switch name := d.Name(); {
+			case name == "testdata", name == "vendor", name == "internal",
+				strings.HasPrefix(name, "."), strings.HasPrefix(name, "_"):
+				return filepath.SkipDir
+			}
+			// nested modules are indexed on their own
+			if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+
+		name, synopsis, ok := parsePackageDoc(p)
+		if !ok {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(moduleDir, p)
+		importPath := modulePath
+		if rel != "." {
+			importPath = path.Join(modulePath, filepath.ToSlash(rel))
+		}
+
+		pkgs = append(pkgs, cache.Package{
+			Name:        name,
+			ImportPath:  importPath,
+			Description: synopsis,
+			Version:     version,
+			ModulePath:  modulePath,
+			InModCache:  true,
+		})
+		return nil
+	})
+
+	return pkgs
+}
+
+// reads the package clause and doc comment of the Go files in dir
+func parsePackageDoc(dir string) (name, synopsis string, ok bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", "", false
+	}
+
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(fileName, ".go") || strings.HasSuffix(fileName, "_test.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, filepath.Join(dir, fileName), nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name.Name
+		}
+		ok = true
+
+		if f.Doc != nil {
+			name = f.Name.Name
+			synopsis = new(doc.Package).Synopsis(f.Doc.Text())
+			break
+		}
+	}
+
+	return name, synopsis, ok
+}
+
+func latestVersion(versions []string) string {
+	latest := ""
+	for _, v := range versions {
+		if latest == "" || semver.Compare(v, latest) > 0 {
+			latest = v
+		}
+	}
+	return latest
+}
@@ -0,0 +1,80 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeModCacheFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(rel))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func newTestModCache(t *testing.T) *ModCache {
+	root := t.TempDir()
+
+	// only downloaded, never extracted
+	writeModCacheFile(t, root, "cache/download/github.com/test/zipped/@v/v1.0.0.info", "{}")
+	writeModCacheFile(t, root, "cache/download/github.com/test/zipped/@v/v1.2.0.mod", "module github.com/test/zipped\n")
+	writeModCacheFile(t, root, "cache/download/github.com/test/zipped/@v/list", "v1.0.0\nv1.2.0\n")
+	writeModCacheFile(t, root, "cache/download/sumdb/sum.golang.org/lookup/github.com/test/zipped@v1.2.0", "")
+
+	// extracted in two versions; upper case letters are escaped with '!'
+	writeModCacheFile(t, root, "github.com/!burnt!sushi/toml@v1.2.0/decode.go", "// Package toml is old.\npackage toml\n")
+	writeModCacheFile(t, root, "github.com/!burnt!sushi/toml@v1.3.2/doc.go", "// Package toml implements decoding and encoding of TOML files.\npackage toml\n")
+	writeModCacheFile(t, root, "github.com/!burnt!sushi/toml@v1.3.2/decode.go", "package toml\n")
+	writeModCacheFile(t, root, "github.com/!burnt!sushi/toml@v1.3.2/decode_test.go", "// Package toml_test tests.\npackage toml_test\n")
+	writeModCacheFile(t, root, "github.com/!burnt!sushi/toml@v1.3.2/cmd/tomlv/main.go", "// Command tomlv validates TOML files.\npackage main\n")
+	writeModCacheFile(t, root, "github.com/!burnt!sushi/toml@v1.3.2/internal/tz.go", "package internal\n")
+	writeModCacheFile(t, root, "github.com/!burnt!sushi/toml@v1.3.2/testdata/bad.go", "package bad\n")
+	writeModCacheFile(t, root, "github.com/!burnt!sushi/toml@v1.3.2/nested/go.mod", "module github.com/BurntSushi/toml/nested\n")
+	writeModCacheFile(t, root, "github.com/!burnt!sushi/toml@v1.3.2/nested/nested.go", "package nested\n")
+
+	return NewModCache(root)
+}
+
+func TestModCacheIndex(t *testing.T) {
+	m := newTestModCache(t)
+
+	results, err := m.Search("toml")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "github.com/BurntSushi/toml", results[0].ImportPath)
+	assert.Equal(t, "toml", results[0].Name)
+	assert.Equal(t, "v1.3.2", results[0].Version)
+	assert.Equal(t, "Package toml implements decoding and encoding of TOML files.", results[0].Description)
+	assert.True(t, results[0].InModCache)
+
+	assert.Equal(t, "github.com/BurntSushi/toml/cmd/tomlv", results[1].ImportPath)
+	assert.Equal(t, "main", results[1].Name)
+	assert.Equal(t, "github.com/BurntSushi/toml", results[1].ModulePath)
+}
+
+func TestModCacheDownloadOnly(t *testing.T) {
+	m := newTestModCache(t)
+
+	pkg, err := m.FetchPackageDetails("github.com/test/zipped")
+	require.NoError(t, err)
+	assert.Equal(t, "zipped", pkg.Name)
+	assert.Equal(t, "v1.2.0", pkg.Version)
+
+	// sumdb entries are not modules
+	results, err := m.Search("sum.golang.org")
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestModCacheMissingRoot(t *testing.T) {
+	m := NewModCache(filepath.Join(t.TempDir(), "missing"))
+
+	results, err := m.Search("anything")
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
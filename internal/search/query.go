@@ -0,0 +1,229 @@
+package search
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+)
+
+// Query is a search box input split into the text sent to the providers and
+// the filters applied to their results
+type Query struct {
+	// everything that is not a local filter, so provider syntax such as
+	// pkg.go.dev's quoted phrases and #symbol searches still works, plus the
+	// filters the providers can narrow the search by themselves
+	Text    string
+	Filters []Filter
+}
+
+// Filter is a key:value operator, or a bare word for "-word" exclusions.
+// A leading "-" negates any filter
+type Filter struct {
+	Key    string
+	Values []string
+	Negate bool
+
+	// for updated: the age limit and whether it is an upper bound (<)
+	age   time.Duration
+	newer bool
+}
+
+var filterKeys = map[string]bool{
+	"license":   true,
+	"std":       true,
+	"host":      true,
+	"installed": true,
+	"updated":   true,
+}
+
+// parses operators like license:MIT, std:false, host:github.com,
+// installed:true, updated:<1y and -word out of raw
+func ParseQuery(raw string) (Query, error) {
+	var q Query
+	var text []string
+
+	for _, field := range strings.Fields(raw) {
+		negate := strings.HasPrefix(field, "-") && len(field) > 1
+		token := field
+		if negate {
+			token = field[1:]
+		}
+
+		key, value, ok := strings.Cut(token, ":")
+		key = strings.ToLower(key)
+		if !ok || !filterKeys[key] {
+			if negate {
+				q.Filters = append(q.Filters, Filter{Values: []string{strings.ToLower(token)}, Negate: true})
+			} else {
+				text = append(text, field)
+			}
+			continue
+		}
+
+		f, err := parseFilter(key, value)
+		if err != nil {
+			return Query{}, err
+		}
+		f.Negate = negate
+		q.Filters = append(q.Filters, f)
+	}
+
+	// pkg.go.dev matches search words against import paths, so a single
+	// host narrows the results upstream; the filter still runs locally to
+	// drop paths that only mention it further in
+	for _, f := range q.Filters {
+		if f.Key == "host" && !f.Negate && len(f.Values) == 1 {
+			text = append(text, f.Values[0])
+		}
+	}
+
+	q.Text = strings.Join(text, " ")
+	return q, nil
+}
+
+func parseFilter(key, value string) (Filter, error) {
+	f := Filter{Key: key}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			f.Values = append(f.Values, v)
+		}
+	}
+	if len(f.Values) == 0 {
+		return f, fmt.Errorf("%s: missing value", key)
+	}
+
+	switch key {
+	case "std", "installed":
+		if _, err := strconv.ParseBool(f.Values[0]); err != nil || len(f.Values) > 1 {
+			return f, fmt.Errorf("%s: want true or false, got %q", key, value)
+		}
+	case "updated":
+		age, newer, err := parseAge(value)
+		if err != nil {
+			return f, fmt.Errorf("updated: %w", err)
+		}
+		f.age, f.newer = age, newer
+	}
+
+	return f, nil
+}
+
+// parses "<1y" (within the last year) or ">6m" (longer ago than six months);
+// units are d, w, m and y
+func parseAge(value string) (time.Duration, bool, error) {
+	if len(value) < 3 || (value[0] != '<' && value[0] != '>') {
+		return 0, false, fmt.Errorf("want <N or >N with a unit of d, w, m or y, got %q", value)
+	}
+
+	n, err := strconv.Atoi(value[1 : len(value)-1])
+	if err != nil || n < 0 {
+		return 0, false, fmt.Errorf("invalid amount in %q", value)
+	}
+
+	day := 24 * time.Hour
+	var unit time.Duration
+	switch value[len(value)-1] {
+	case 'd':
+		unit = day
+	case 'w':
+		unit = 7 * day
+	case 'm':
+		unit = 30 * day
+	case 'y':
+		unit = 365 * day
+	default:
+		return 0, false, fmt.Errorf("unknown unit in %q", value)
+	}
+
+	return time.Duration(n) * unit, value[0] == '<', nil
+}
+
+// keeps the packages that pass every filter. Packages whose provider gave
+// no license or date can't be judged by those filters; they are kept with
+// the filters listed in Unverified
+func (q Query) Filter(pkgs []cache.Package, now time.Time) []cache.Package {
+	if len(q.Filters) == 0 {
+		return pkgs
+	}
+
+	kept := make([]cache.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		ok := true
+		pkg.Unverified = nil
+		for _, f := range q.Filters {
+			match, known := f.match(pkg, now)
+			if !known {
+				pkg.Unverified = append(pkg.Unverified, f.Key)
+				continue
+			}
+			if match == f.Negate {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			kept = append(kept, pkg)
+		}
+	}
+	return kept
+}
+
+// reports whether pkg matches f, and whether pkg carries what f looks at
+func (f Filter) match(pkg cache.Package, now time.Time) (bool, bool) {
+	switch f.Key {
+	case "":
+		word := f.Values[0]
+		return strings.Contains(strings.ToLower(pkg.Name), word) ||
+			strings.Contains(strings.ToLower(pkg.ImportPath), word) ||
+			strings.Contains(strings.ToLower(pkg.Description), word), true
+
+	case "license":
+		if strings.TrimSpace(pkg.License) == "" {
+			return false, false
+		}
+		for _, id := range strings.Split(pkg.License, ",") {
+			id = strings.ToLower(strings.TrimSpace(id))
+			for _, want := range f.Values {
+				if ok, _ := path.Match(strings.ToLower(want), id); ok && id != "" {
+					return true, true
+				}
+			}
+		}
+		return false, true
+
+	case "std":
+		want, _ := strconv.ParseBool(f.Values[0])
+		return pkg.Stdlib == want, true
+
+	case "host":
+		host, _, _ := strings.Cut(pkg.ImportPath, "/")
+		host = strings.ToLower(host)
+		for _, want := range f.Values {
+			want = strings.ToLower(want)
+			if host == want || strings.HasSuffix(host, "."+want) {
+				return true, true
+			}
+		}
+		return false, true
+
+	case "installed":
+		want, _ := strconv.ParseBool(f.Values[0])
+		// install status comes from go.mod, the module cache and GOBIN
+		// whatever the provider, so it is always known
+		return (pkg.IsInstalled || pkg.InModule || pkg.InModCache || pkg.InGoBin) == want, true
+
+	case "updated":
+		published, err := time.Parse("2006-01-02", pkg.Published)
+		if err != nil {
+			return false, false
+		}
+		within := now.Sub(published) <= f.age
+		return within == f.newer, true
+	}
+
+	return true, true
+}
@@ -0,0 +1,78 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+)
+
+func TestParseQuery(t *testing.T) {
+	q, err := ParseQuery(`http "router" license:MIT,BSD* -host:gitlab.com -gin #Handler foo:bar`)
+	require.NoError(t, err)
+	assert.Equal(t, `http "router" #Handler foo:bar`, q.Text, "unknown operators go to the provider")
+	require.Len(t, q.Filters, 3)
+	assert.Equal(t, []string{"MIT", "BSD*"}, q.Filters[0].Values)
+	assert.True(t, q.Filters[1].Negate)
+	assert.Equal(t, Filter{Values: []string{"gin"}, Negate: true}, q.Filters[2])
+
+	q, err = ParseQuery("router host:github.com")
+	require.NoError(t, err)
+	assert.Equal(t, "router github.com", q.Text, "a single host is searched for upstream")
+
+	for _, raw := range []string{"x std:maybe", "x license:", "x updated:1y", "x updated:<1h", "x updated:<y"} {
+		_, err := ParseQuery(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestQueryFilter(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	pkgs := []cache.Package{
+		{Name: "http", ImportPath: "net/http", Stdlib: true, License: "BSD-3-Clause"},
+		{Name: "gin", ImportPath: "github.com/gin-gonic/gin", License: "MIT", Published: "2024-03-01", InModule: true},
+		{Name: "chi", ImportPath: "github.com/go-chi/chi", License: "MIT", Published: "2021-01-01"},
+		{Name: "mux", ImportPath: "gitlab.com/x/mux", License: "Apache-2.0, MIT", Description: "Deprecated router"},
+		{Name: "echo", ImportPath: "go.labstack.com/echo", License: "MIT"},
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"router std:false", []string{"github.com/gin-gonic/gin", "github.com/go-chi/chi", "gitlab.com/x/mux", "go.labstack.com/echo"}},
+		{"router std:true", []string{"net/http"}},
+		{"router license:mit host:github.com,gitlab.com", []string{"github.com/gin-gonic/gin", "github.com/go-chi/chi", "gitlab.com/x/mux"}},
+		{"router license:apache*", []string{"gitlab.com/x/mux"}},
+		{"router installed:true", []string{"github.com/gin-gonic/gin"}},
+		{"router -deprecated -gin -license:BSD*", []string{"github.com/go-chi/chi", "go.labstack.com/echo"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, "router", q.Text)
+			assert.Equal(t, tt.want, importPaths(q.Filter(pkgs, now)))
+		})
+	}
+}
+
+func TestQueryFilterKeepsUnverified(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	pkgs := []cache.Package{
+		{ImportPath: "example.com/new", License: "MIT", Published: "2024-03-01"},
+		{ImportPath: "example.com/old", License: "GPL-3.0", Published: "2021-01-01"},
+		{ImportPath: "example.com/unknown"},
+	}
+
+	q, err := ParseQuery("lib updated:<1y -license:GPL*")
+	require.NoError(t, err)
+	kept := q.Filter(pkgs, now)
+	assert.Equal(t, []string{"example.com/new", "example.com/unknown"}, importPaths(kept))
+	assert.Empty(t, kept[0].Unverified)
+	assert.Equal(t, []string{"updated", "license"}, kept[1].Unverified)
+}
@@ -0,0 +1,197 @@
+package search
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+	"github.com/MdSadiqMd/gopick/internal/history"
+)
+
+type SortMode int
+
+const (
+	SortRelevance SortMode = iota
+	SortImportedBy
+	SortStars
+	SortPublished
+	SortInstalled
+)
+
+var sortModeNames = []string{"relevance", "imported-by", "stars", "published", "installed"}
+
+func (s SortMode) String() string {
+	if int(s) < len(sortModeNames) {
+		return sortModeNames[s]
+	}
+	return fmt.Sprintf("SortMode(%d)", int(s))
+}
+
+// returns the mode after s, wrapping around to relevance
+func (s SortMode) Next() SortMode {
+	return (s + 1) % SortMode(len(sortModeNames))
+}
+
+// parses a mode name as printed by String
+func ParseSortMode(name string) (SortMode, error) {
+	for i, n := range sortModeNames {
+		if strings.EqualFold(name, n) {
+			return SortMode(i), nil
+		}
+	}
+	return SortRelevance, fmt.Errorf("unknown sort mode %q (want one of %s)", name, strings.Join(sortModeNames, ", "))
+}
+
+// each point of boost moves a result this many places up
+const boostWeight = 2
+
+const (
+	maxHistoryBoost = 6
+	goModBoost      = 2
+)
+
+// Booster holds the local signals that lift results above the provider's
+// order: packages from the history and modules required by team go.mod files
+type Booster struct {
+	imports map[string]int
+	modules map[string]int
+}
+
+// scores history entries and reads the given go.mod files, which may also be
+// directories holding one or glob patterns. Unreadable files are skipped
+func NewBooster(entries []history.Entry, goMods []string) *Booster {
+	b := &Booster{
+		imports: make(map[string]int),
+		modules: make(map[string]int),
+	}
+
+	for _, e := range entries {
+		switch e.Action {
+		case history.ActionViewed:
+			b.imports[e.ImportPath]++
+		case history.ActionInstalled, history.ActionUpgraded, history.ActionDowngraded:
+			b.imports[e.ImportPath] += 3
+		}
+	}
+	for importPath, score := range b.imports {
+		if score > maxHistoryBoost {
+			b.imports[importPath] = maxHistoryBoost
+		}
+	}
+
+	for _, pattern := range goMods {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if info, err := os.Stat(file); err == nil && info.IsDir() {
+				file = filepath.Join(file, "go.mod")
+			}
+			b.addGoMod(file)
+		}
+	}
+
+	return b
+}
+
+func (b *Booster) addGoMod(file string) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return
+	}
+	mf, err := modfile.ParseLax(file, data, nil)
+	if err != nil {
+		return
+	}
+
+	for _, r := range mf.Require {
+		if !r.Indirect {
+			b.modules[r.Mod.Path] += goModBoost
+		}
+	}
+}
+
+// returns how far pkg should be lifted; a nil Booster lifts nothing
+func (b *Booster) Score(pkg cache.Package) int {
+	if b == nil {
+		return 0
+	}
+
+	score := b.imports[pkg.ImportPath]
+	// the module may not be resolved yet, so try each prefix of the path
+	for p := pkg.ImportPath; p != "." && p != "/"; p = path.Dir(p) {
+		if n, ok := b.modules[p]; ok {
+			score += n
+			break
+		}
+	}
+	return score
+}
+
+// sets the relevance rank of results in the order the provider returned
+// them, lifted by local boosts, and sorts them by it
+func Rank(pkgs []cache.Package, b *Booster) {
+	for i := range pkgs {
+		pkgs[i].Rank = float64(i - boostWeight*b.Score(pkgs[i]))
+	}
+	Sort(pkgs, SortRelevance)
+}
+
+// orders results by mode, most relevant first among equals. Results
+// without the metric go last
+func Sort(pkgs []cache.Package, mode SortMode) {
+	sort.SliceStable(pkgs, func(i, j int) bool {
+		a, b := pkgs[i], pkgs[j]
+
+		switch mode {
+		case SortImportedBy:
+			if a.ImportedBy != b.ImportedBy {
+				return a.ImportedBy > b.ImportedBy
+			}
+		case SortStars:
+			if a.Stars != b.Stars {
+				return a.Stars > b.Stars
+			}
+		case SortPublished:
+			// dates are normalized to 2006-01-02
+			if a.Published != b.Published {
+				return a.Published > b.Published
+			}
+		case SortInstalled:
+			if ia, ib := installedOrder(a), installedOrder(b); ia != ib {
+				return ia < ib
+			}
+		}
+
+		return a.Rank < b.Rank
+	})
+}
+
+func installedOrder(pkg cache.Package) int {
+	switch {
+	case pkg.InModule || pkg.Tool:
+		return 0
+	case pkg.IsInstalled || pkg.InModCache || pkg.InGoBin:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// reports whether any result carries a star count, which only some
+// providers supply
+func HasStars(pkgs []cache.Package) bool {
+	for _, pkg := range pkgs {
+		if pkg.Stars > 0 {
+			return true
+		}
+	}
+	return false
+}
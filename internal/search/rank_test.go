@@ -0,0 +1,88 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+	"github.com/MdSadiqMd/gopick/internal/history"
+)
+
+func importPaths(pkgs []cache.Package) []string {
+	var paths []string
+	for _, pkg := range pkgs {
+		paths = append(paths, pkg.ImportPath)
+	}
+	return paths
+}
+
+func rankedPackages() []cache.Package {
+	return []cache.Package{
+		{ImportPath: "example.com/a", ImportedBy: 10, Published: "2023-01-01"},
+		{ImportPath: "example.com/b", ImportedBy: 500, Stars: 3},
+		{ImportPath: "example.com/c", ImportedBy: 10, Published: "2024-06-01", InModCache: true, IsInstalled: true},
+		{ImportPath: "example.com/d", Stars: 40, InModule: true, IsInstalled: true},
+	}
+}
+
+func TestSort(t *testing.T) {
+	tests := []struct {
+		mode SortMode
+		want []string
+	}{
+		{SortRelevance, []string{"example.com/a", "example.com/b", "example.com/c", "example.com/d"}},
+		{SortImportedBy, []string{"example.com/b", "example.com/a", "example.com/c", "example.com/d"}},
+		{SortStars, []string{"example.com/d", "example.com/b", "example.com/a", "example.com/c"}},
+		{SortPublished, []string{"example.com/c", "example.com/a", "example.com/b", "example.com/d"}},
+		{SortInstalled, []string{"example.com/d", "example.com/c", "example.com/a", "example.com/b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			pkgs := rankedPackages()
+			Rank(pkgs, nil)
+			Sort(pkgs, tt.mode)
+			assert.Equal(t, tt.want, importPaths(pkgs))
+
+			// going back to relevance restores the provider order
+			Sort(pkgs, SortRelevance)
+			assert.Equal(t, tests[0].want, importPaths(pkgs))
+		})
+	}
+}
+
+func TestSortModeNames(t *testing.T) {
+	assert.Equal(t, SortImportedBy, SortRelevance.Next())
+	assert.Equal(t, SortRelevance, SortInstalled.Next())
+
+	mode, err := ParseSortMode("Imported-By")
+	require.NoError(t, err)
+	assert.Equal(t, SortImportedBy, mode)
+
+	_, err = ParseSortMode("downloads")
+	assert.Error(t, err)
+}
+
+func TestRankBoosts(t *testing.T) {
+	teamDir := filepath.Join(t.TempDir(), "service")
+	require.NoError(t, os.MkdirAll(teamDir, 0755))
+	goMod := "module example.com/service\n\ngo 1.21\n\nrequire (\n\texample.com/d v1.0.0\n\texample.com/c v1.0.0 // indirect\n)\n"
+	require.NoError(t, os.WriteFile(filepath.Join(teamDir, "go.mod"), []byte(goMod), 0644))
+
+	b := NewBooster([]history.Entry{
+		{ImportPath: "example.com/c", Action: history.ActionInstalled},
+		{ImportPath: "example.com/b", Action: history.ActionRemoved},
+	}, []string{filepath.Join(filepath.Dir(teamDir), "*"), filepath.Join(t.TempDir(), "missing")})
+
+	assert.Equal(t, 3, b.Score(cache.Package{ImportPath: "example.com/c"}))
+	assert.Equal(t, 2, b.Score(cache.Package{ImportPath: "example.com/d/sub"}), "nested packages count for their module")
+	assert.Equal(t, 0, b.Score(cache.Package{ImportPath: "example.com/b"}))
+
+	pkgs := rankedPackages()
+	Rank(pkgs, b)
+	assert.Equal(t, []string{"example.com/c", "example.com/d", "example.com/a", "example.com/b"}, importPaths(pkgs))
+}
@@ -0,0 +1,86 @@
+package search
+
+import (
+	"fmt"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+	"github.com/MdSadiqMd/gopick/internal/config"
+	"github.com/MdSadiqMd/gopick/internal/scraper"
+)
+
+// Searcher is implemented by every package metadata provider
+type Searcher interface {
+	Search(query string) ([]cache.Package, error)
+	FetchPackageDetails(importPath string) (*cache.Package, error)
+}
+
+// builds the searcher described by the configured providers, chaining them
+// when more than one is configured and falling back to the offline ones
+func FromConfig(cfg *config.Config) (Searcher, error) {
+	providers := cfg.Providers
+	if len(providers) == 0 {
+		providers = []config.Provider{{Type: config.ProviderPkgGoDev}}
+	}
+
+	primary, err := newSearcher(cfg, providers)
+	if err != nil {
+		return nil, err
+	}
+
+	fallbackProviders := cfg.FallbackProviders
+	if fallbackProviders == nil {
+		fallbackProviders = []config.Provider{{Type: config.ProviderModCache}}
+	}
+	if len(fallbackProviders) == 0 {
+		return primary, nil
+	}
+
+	fallback, err := newSearcher(cfg, fallbackProviders)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFallback(primary, fallback), nil
+}
+
+func newSearcher(cfg *config.Config, providers []config.Provider) (Searcher, error) {
+	var searchers []Searcher
+	for _, p := range providers {
+		s, err := newProvider(cfg, p)
+		if err != nil {
+			return nil, err
+		}
+		searchers = append(searchers, s)
+	}
+
+	if len(searchers) == 1 {
+		return searchers[0], nil
+	}
+
+	return NewChain(searchers...), nil
+}
+
+func newProvider(cfg *config.Config, p config.Provider) (Searcher, error) {
+	switch p.Type {
+	case config.ProviderPkgGoDev:
+		return scraper.New(), nil
+	case config.ProviderModCache:
+		root := p.Path
+		if root == "" {
+			root = cfg.GoModCachePath
+		}
+		return NewModCache(root), nil
+	case config.ProviderCatalog:
+		if p.Path == "" {
+			return nil, fmt.Errorf("catalog provider requires a path")
+		}
+		return NewCatalog(p.Path)
+	case config.ProviderHTTP:
+		if p.URL == "" {
+			return nil, fmt.Errorf("http provider requires a url")
+		}
+		return NewHTTPCatalog(p.URL), nil
+	default:
+		return nil, fmt.Errorf("unknown search provider %q", p.Type)
+	}
+}
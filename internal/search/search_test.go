@@ -0,0 +1,184 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MdSadiqMd/gopick/internal/cache"
+	"github.com/MdSadiqMd/gopick/internal/config"
+	"github.com/MdSadiqMd/gopick/internal/scraper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSearcher struct {
+	packages []cache.Package
+	err      error
+}
+
+func (f *fakeSearcher) Search(query string) ([]cache.Package, error) {
+	return f.packages, f.err
+}
+
+func (f *fakeSearcher) FetchPackageDetails(importPath string) (*cache.Package, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return findPackage(f.packages, importPath)
+}
+
+var testPackages = []cache.Package{
+	{Name: "toml", ImportPath: "github.com/BurntSushi/toml", Description: "TOML parser for Go"},
+	{Name: "yaml", ImportPath: "gopkg.in/yaml.v3", Description: "YAML support for Go"},
+	{Name: "auth", ImportPath: "corp.example.com/platform/auth", Description: "Internal auth client"},
+}
+
+func writeCatalog(t *testing.T, packages []cache.Package) string {
+	t.Helper()
+
+	data, err := json.Marshal(packages)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func TestFromConfig(t *testing.T) {
+	cfg := config.DefaultConfig()
+
+	s, err := FromConfig(cfg)
+	require.NoError(t, err)
+	require.IsType(t, &Fallback{}, s)
+	assert.IsType(t, &scraper.Scraper{}, s.(*Fallback).primary)
+	assert.IsType(t, &ModCache{}, s.(*Fallback).fallback)
+
+	cfg.FallbackProviders = []config.Provider{}
+	cfg.Providers = []config.Provider{
+		{Type: config.ProviderPkgGoDev},
+		{Type: config.ProviderCatalog, Path: writeCatalog(t, testPackages)},
+		{Type: config.ProviderHTTP, URL: "https://catalog.example.com/search"},
+	}
+	s, err = FromConfig(cfg)
+	require.NoError(t, err)
+	require.IsType(t, &Chain{}, s)
+	assert.Len(t, s.(*Chain).searchers, 3)
+
+	cfg.Providers = []config.Provider{{Type: "bogus"}}
+	_, err = FromConfig(cfg)
+	assert.Error(t, err)
+
+	cfg.Providers = []config.Provider{{Type: config.ProviderCatalog}}
+	_, err = FromConfig(cfg)
+	assert.Error(t, err)
+}
+
+func TestCatalog(t *testing.T) {
+	c, err := NewCatalog(writeCatalog(t, testPackages))
+	require.NoError(t, err)
+
+	results, err := c.Search("go parser")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "github.com/BurntSushi/toml", results[0].ImportPath)
+
+	results, err = c.Search("CORP")
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	results, err = c.Search("")
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	pkg, err := c.FetchPackageDetails("gopkg.in/yaml.v3")
+	require.NoError(t, err)
+	assert.Equal(t, "yaml", pkg.Name)
+
+	_, err = c.FetchPackageDetails("github.com/missing/pkg")
+	assert.Error(t, err)
+
+	_, err = NewCatalog(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestHTTPCatalog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(filterPackages(testPackages, r.URL.Query().Get("q")))
+	}))
+	defer server.Close()
+
+	h := NewHTTPCatalog(server.URL)
+
+	results, err := h.Search("auth")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "corp.example.com/platform/auth", results[0].ImportPath)
+
+	pkg, err := h.FetchPackageDetails("corp.example.com/platform/auth")
+	require.NoError(t, err)
+	assert.Equal(t, "Internal auth client", pkg.Description)
+}
+
+func TestChainMergesAndDeduplicates(t *testing.T) {
+	first := &fakeSearcher{packages: testPackages[:2]}
+	second := &fakeSearcher{packages: []cache.Package{
+		{Name: "toml-dup", ImportPath: "github.com/BurntSushi/toml"},
+		testPackages[2],
+	}}
+	broken := &fakeSearcher{err: errors.New("offline")}
+
+	c := NewChain(first, broken, second)
+
+	results, err := c.Search("anything")
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, "toml", results[0].Name)
+	assert.Equal(t, "corp.example.com/platform/auth", results[2].ImportPath)
+
+	pkg, err := c.FetchPackageDetails("corp.example.com/platform/auth")
+	require.NoError(t, err)
+	assert.Equal(t, "auth", pkg.Name)
+}
+
+func TestFallback(t *testing.T) {
+	online := &fakeSearcher{packages: testPackages[:1]}
+	offline := &fakeSearcher{packages: testPackages[1:]}
+	broken := &fakeSearcher{err: errors.New("network is unreachable")}
+
+	results, err := NewFallback(online, offline).Search("go")
+	require.NoError(t, err)
+	assert.Equal(t, testPackages[:1], results)
+
+	results, err = NewFallback(broken, offline).Search("go")
+	require.NoError(t, err)
+	assert.Equal(t, testPackages[1:], results)
+
+	_, fallback, err := SearchSource(NewFallback(online, offline), "go")
+	require.NoError(t, err)
+	assert.False(t, fallback)
+	_, fallback, err = SearchSource(NewFallback(broken, offline), "go")
+	require.NoError(t, err)
+	assert.True(t, fallback)
+
+	pkg, err := NewFallback(broken, offline).FetchPackageDetails("gopkg.in/yaml.v3")
+	require.NoError(t, err)
+	assert.Equal(t, "yaml", pkg.Name)
+
+	_, err = NewFallback(broken, broken).Search("go")
+	assert.Error(t, err)
+}
+
+func TestChainAllFail(t *testing.T) {
+	c := NewChain(&fakeSearcher{err: errors.New("a")}, &fakeSearcher{err: errors.New("b")})
+
+	_, err := c.Search("anything")
+	assert.Error(t, err)
+
+	_, err = c.FetchPackageDetails("github.com/test/pkg")
+	assert.Error(t, err)
+}
@@ -0,0 +1,50 @@
+# gopick shell integration for bash
+#
+# Runs gopick with GOPICK_OUTPUT_FILE set, so the picked command comes back
+# through a temp file instead of being typed into the terminal with TIOCSTI.
+# A second line "run" asks for the command to be run rather than shown.
+
+# runs gopick and stores the picked command in __gopick_cmd and whether to
+# run it in __gopick_action
+__gopick_run() {
+    local out ret
+    __gopick_cmd=
+    __gopick_action=
+    out=$(mktemp "${TMPDIR:-/tmp}/gopick.XXXXXX") || return 1
+    GOPICK_OUTPUT_FILE=$out command gopick "$@"
+    ret=$?
+    { IFS= read -r __gopick_cmd; IFS= read -r __gopick_action; } <"$out"
+    rm -f -- "$out"
+    return $ret
+}
+
+# bind -x widget: inserts the picked command at the cursor, or runs it
+__gopick_widget() {
+    __gopick_run
+    [[ -n $__gopick_cmd ]] || return
+    if [[ $__gopick_action == run ]]; then
+        # bind -x can't accept the line, so run the command here
+        history -s -- "$__gopick_cmd"
+        printf '%s\n' "$__gopick_cmd"
+        eval -- "$__gopick_cmd"
+        return
+    fi
+    READLINE_LINE="${READLINE_LINE:0:READLINE_POINT}${__gopick_cmd}${READLINE_LINE:READLINE_POINT}"
+    READLINE_POINT=$((READLINE_POINT + ${#__gopick_cmd}))
+}
+
+gopick() {
+    __gopick_run "$@"
+    local ret=$?
+    if [[ -n $__gopick_cmd ]]; then
+        # bash can't prefill the next prompt from a plain command, so the
+        # command becomes the newest history entry: press Up to run it
+        history -s -- "$__gopick_cmd"
+        printf '%s\n' "$__gopick_cmd"
+        if [[ $__gopick_action == run ]]; then
+            eval -- "$__gopick_cmd"
+            ret=$?
+        fi
+    fi
+    return $ret
+}
@@ -0,0 +1,50 @@
+# gopick shell integration for fish
+#
+# Runs gopick with GOPICK_OUTPUT_FILE set, so the picked command comes back
+# through a temp file instead of being typed into the terminal with TIOCSTI.
+# A second line "run" asks for the command to be run rather than shown.
+
+# runs gopick and stores the picked command in __gopick_cmd and whether to
+# run it in __gopick_action
+function __gopick_run
+    set -g __gopick_cmd
+    set -g __gopick_action
+    set -l out (mktemp -t gopick.XXXXXX); or return 1
+    GOPICK_OUTPUT_FILE=$out command gopick $argv
+    set -l ret $status
+    set -l lines (cat $out)
+    set -g __gopick_cmd $lines[1]
+    set -g __gopick_action $lines[2]
+    rm -f -- $out
+    return $ret
+end
+
+# key binding: inserts the picked command at the cursor, or runs it
+function __gopick_widget
+    __gopick_run
+    if test -n "$__gopick_cmd"
+        commandline -i -- $__gopick_cmd
+        if test "$__gopick_action" = run
+            commandline -f execute
+            return
+        end
+    end
+    commandline -f repaint
+end
+
+function gopick --description 'Search and install Go packages'
+    __gopick_run $argv
+    set -l ret $status
+    test -n "$__gopick_cmd"; or return $ret
+    if test "$__gopick_action" = run
+        echo $__gopick_cmd
+        eval $__gopick_cmd
+        return
+    end
+    # the buffer can only be set while reading, so wait for the next prompt
+    function __gopick_prefill --on-event fish_prompt
+        functions -e __gopick_prefill
+        commandline -r -- $__gopick_cmd
+    end
+    return $ret
+end
@@ -0,0 +1,55 @@
+// Package shell holds the wrapper functions that let bash, zsh and fish put
+// the command picked in gopick on the prompt without TIOCSTI
+package shell
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+)
+
+//go:embed gopick.bash gopick.zsh gopick.fish
+var scripts embed.FS
+
+// ErrUnsupported is returned for shells without an integration script
+var ErrUnsupported = errors.New("unsupported shell")
+
+// Shells lists the supported shells
+var Shells = []string{"bash", "zsh", "fish"}
+
+// key bindings that open gopick with Ctrl-G and splice the picked command
+// into the current command line
+var bindings = map[string]string{
+	"bash": `if [[ $- == *i* ]]; then
+    bind -x '"\C-g": __gopick_widget'
+fi
+`,
+	"zsh": `bindkey '^G' __gopick_widget
+`,
+	"fish": `bind \cg __gopick_widget
+bind -M insert \cg __gopick_widget 2>/dev/null
+`,
+}
+
+// returns the snippet printed by gopick shell-init: the wrapper functions
+// and, if bind is set, the Ctrl-G key binding
+func Init(name string, bind bool) (string, error) {
+	script, err := Script(name)
+	if err != nil {
+		return "", err
+	}
+
+	if bind {
+		script += "\n# Ctrl-G opens gopick and inserts the picked command at the cursor\n" + bindings[name]
+	}
+	return script, nil
+}
+
+// returns the integration script for the named shell
+func Script(name string) (string, error) {
+	data, err := scripts.ReadFile("gopick." + name)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s (supported: bash, zsh, fish)", ErrUnsupported, name)
+	}
+	return string(data), nil
+}
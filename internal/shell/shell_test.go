@@ -0,0 +1,66 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScript(t *testing.T) {
+	buffers := map[string]string{
+		"bash": "READLINE_LINE",
+		"zsh":  "print -z",
+		"fish": "commandline",
+	}
+
+	for _, name := range Shells {
+		script, err := Script(name)
+		require.NoError(t, err, name)
+		assert.Contains(t, script, "GOPICK_OUTPUT_FILE", name)
+		assert.Contains(t, script, buffers[name], name)
+	}
+
+	_, err := Script("powershell")
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestInit(t *testing.T) {
+	snippet, err := Init("bash", true)
+	require.NoError(t, err)
+	assert.Contains(t, snippet, "gopick() {")
+	assert.Contains(t, snippet, `bind -x '"\C-g": __gopick_widget'`)
+
+	snippet, err = Init("zsh", false)
+	require.NoError(t, err)
+	assert.NotContains(t, snippet, "bindkey")
+
+	snippet, err = Init("fish", true)
+	require.NoError(t, err)
+	assert.Contains(t, snippet, `bind \cg __gopick_widget`)
+
+	_, err = Init("tcsh", true)
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+// checks the snippets parse with the shells installed on this machine
+func TestScriptSyntax(t *testing.T) {
+	for _, name := range Shells {
+		path, err := exec.LookPath(name)
+		if err != nil {
+			continue
+		}
+
+		script, err := Init(name, true)
+		require.NoError(t, err)
+
+		file := filepath.Join(t.TempDir(), "gopick."+name)
+		require.NoError(t, os.WriteFile(file, []byte(script), 0644))
+
+		output, err := exec.Command(path, "-n", file).CombinedOutput()
+		assert.NoError(t, err, "%s: %s", name, output)
+	}
+}
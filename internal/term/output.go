@@ -0,0 +1,55 @@
+package term
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const (
+	// EnvOutputFile names a file the shell wrapper reads the picked command from
+	EnvOutputFile = "GOPICK_OUTPUT_FILE"
+	// EnvOutputFD names an inherited file descriptor to write the picked command to
+	EnvOutputFD = "GOPICK_OUTPUT_FD"
+)
+
+// ErrNoShellIntegration is returned when gopick was not started by a shell wrapper
+var ErrNoShellIntegration = errors.New("shell integration not enabled")
+
+// runAction is the second line of the output, telling the wrapper to run the
+// command instead of leaving it on the prompt
+const runAction = "run"
+
+// WriteCommandToShell hands command to the shell wrapper that started gopick,
+// which places it on the prompt itself, or runs it when run is set. This
+// works where TIOCSTI is disabled
+func WriteCommandToShell(command string, run bool) error {
+	output := command + "\n"
+	if run {
+		output += runAction + "\n"
+	}
+
+	if path := os.Getenv(EnvOutputFile); path != "" {
+		if err := os.WriteFile(path, []byte(output), 0600); err != nil {
+			return fmt.Errorf("failed to write command to %s: %w", path, err)
+		}
+		return nil
+	}
+
+	if value := os.Getenv(EnvOutputFD); value != "" {
+		fd, err := strconv.Atoi(value)
+		if err != nil || fd < 3 {
+			return fmt.Errorf("invalid %s %q", EnvOutputFD, value)
+		}
+
+		f := os.NewFile(uintptr(fd), "gopick-output")
+		defer f.Close()
+		if _, err := f.WriteString(output); err != nil {
+			return fmt.Errorf("failed to write command to fd %d: %w", fd, err)
+		}
+		return nil
+	}
+
+	return ErrNoShellIntegration
+}
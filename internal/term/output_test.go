@@ -0,0 +1,63 @@
+package term
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestWriteCommandToShellFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out")
+	t.Setenv(EnvOutputFile, path)
+	t.Setenv(EnvOutputFD, "")
+
+	require.NoError(t, WriteCommandToShell("go get github.com/test/pkg", false))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "go get github.com/test/pkg\n", string(data))
+
+	// auto-run asks the wrapper to run the command
+	require.NoError(t, WriteCommandToShell("go get github.com/test/pkg", true))
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "go get github.com/test/pkg\nrun\n", string(data))
+}
+
+func TestWriteCommandToShellFD(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+	defer w.Close()
+
+	// the command is written to, and closes, its own copy of the descriptor
+	fd, err := unix.Dup(int(w.Fd()))
+	require.NoError(t, err)
+
+	t.Setenv(EnvOutputFile, "")
+	t.Setenv(EnvOutputFD, strconv.Itoa(fd))
+
+	require.NoError(t, WriteCommandToShell("go get github.com/test/pkg", false))
+
+	buf := make([]byte, 64)
+	n, err := r.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "go get github.com/test/pkg\n", string(buf[:n]))
+
+	// stdio is never a valid target
+	t.Setenv(EnvOutputFD, "1")
+	assert.Error(t, WriteCommandToShell("go get github.com/test/pkg", false))
+}
+
+func TestWriteCommandToShellDisabled(t *testing.T) {
+	t.Setenv(EnvOutputFile, "")
+	t.Setenv(EnvOutputFD, "")
+
+	assert.ErrorIs(t, WriteCommandToShell("go get github.com/test/pkg", false), ErrNoShellIntegration)
+}
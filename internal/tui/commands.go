@@ -1,25 +1,118 @@
 package tui
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/MdSadiqMd/gopick/internal/cache"
+	"github.com/MdSadiqMd/gopick/internal/history"
+	"github.com/MdSadiqMd/gopick/internal/imports"
+	"github.com/MdSadiqMd/gopick/internal/license"
+	"github.com/MdSadiqMd/gopick/internal/packages"
+	"github.com/MdSadiqMd/gopick/internal/proxy"
+	"github.com/MdSadiqMd/gopick/internal/search"
+	"github.com/MdSadiqMd/gopick/internal/vuln"
+	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
 )
 
 type searchResultsMsg struct {
+	// the search box input the results are for
+	query     string
 	packages  []cache.Package
 	fromCache bool
-	err       error
+	// answered by the fallback providers, e.g. the module cache while offline
+	offline bool
+	// the query is still being typed, e.g. "license:" or a lone filter;
+	// the current results stay
+	incomplete bool
+	err        error
+}
+
+// install status of results, refreshed after go.mod changed
+type installedMsg struct {
+	packages []cache.Package
+}
+
+type versionsMsg struct {
+	importPath string
+	versions   []proxy.Version
+	err        error
+}
+
+type versionTimesMsg struct {
+	modulePath string
+	times      map[string]time.Time
+}
+
+type detailsMsg struct {
+	importPath string
+	pkg        *cache.Package
+	err        error
+}
+
+type previewMsg struct {
+	previews []modulePreview
+}
+
+// import paths of selected packages found to be package main
+type commandsMsg struct {
+	importPaths []string
+}
+
+// licenses detected for the selected packages, by import path
+type licensesMsg struct {
+	licenses map[string]string
+}
+
+// findings per module version; err is set when the database could not be read
+type vulnsMsg struct {
+	found map[module.Version][]vuln.Finding
+	err   error
+}
+
+type outdatedMsg struct {
+	updates []packages.Update
+	err     error
+}
+
+// search state put aside while the outdated report uses the result list
+type savedSearch struct {
+	packages []cache.Package
+	selected map[int]bool
+	cursor   int
+}
+
+type modulePreview struct {
+	module  packages.Module
+	preview *packages.Preview
+	err     error
 }
 
 type installProgressMsg struct {
 	percent float64
 	message string
-	done    bool
+	// set once a package finished installing
+	installed *cache.Package
+	action    history.ActionType
+	done      bool
 }
 
+// reports a package that failed to install; the remaining ones continue
 type installErrorMsg struct {
+	pkg cache.Package
+	err error
+}
+
+type installFailure struct {
+	pkg cache.Package
 	err error
 }
 
@@ -45,39 +138,70 @@ func (m *Model) debounceSearch() tea.Cmd {
 	}
 }
 
-func (m *Model) performSearch(query string) tea.Cmd {
+func (m *Model) performSearch(raw string) tea.Cmd {
 	return func() tea.Msg {
-		if cached, found := m.cache.Get(query); found {
-			packages := m.pkgManager.MarkInstalledPackages(cached.Results)
-			return searchResultsMsg{
-				packages:  packages,
-				fromCache: true,
-			}
-		}
+		msg := m.runSearch(raw)
+		msg.query = raw
+		return msg
+	}
+}
 
-		packages, err := m.scraper.Search(query)
-		if err != nil {
-			if cached, found := m.cache.Get(query); found {
-				packages = cached.Results
-			} else {
-				return searchResultsMsg{err: err}
-			}
-		}
+func (m *Model) runSearch(raw string) searchResultsMsg {
+	// a filter without a value or without search terms yet is not worth a
+	// provider search, let alone an error while typing
+	q, err := search.ParseQuery(raw)
+	if err != nil || q.Text == "" {
+		return searchResultsMsg{incomplete: true}
+	}
+	// only the provider query is cached; filters apply on every search
+	query := q.Text
 
-		packages = m.pkgManager.MarkInstalledPackages(packages)
-		if err == nil {
-			m.cache.Set(query, packages)
+	if cached, found := m.cache.Get(query); found {
+		packages := m.markInstalled(cached.Results)
+		return searchResultsMsg{
+			packages:  q.Filter(packages, time.Now()),
+			fromCache: true,
 		}
+	}
 
-		return searchResultsMsg{
-			packages:  packages,
-			fromCache: false,
+	packages, offline, err := search.SearchSource(m.searcher, query)
+	if err != nil {
+		if cached, found := m.cache.Get(query); found {
+			packages = cached.Results
+		} else {
+			return searchResultsMsg{err: err}
 		}
 	}
+
+	packages = m.markInstalled(packages)
+	// offline answers would otherwise outlive the outage for the cache TTL
+	if err == nil && !offline {
+		m.cache.Set(query, packages)
+	}
+
+	return searchResultsMsg{
+		packages:  q.Filter(packages, time.Now()),
+		fromCache: false,
+		offline:   offline,
+	}
+}
+
+// resolves the modules of pkgs through the proxy, then fills in their
+// install status. Only for commands: it waits on the network
+func (m *Model) markInstalled(pkgs []cache.Package) []cache.Package {
+	var importPaths []string
+	for _, pkg := range pkgs {
+		importPaths = append(importPaths, pkg.ImportPath)
+	}
+	m.pkgManager.ResolveModules(importPaths)
+	return m.pkgManager.MarkInstalledPackages(pkgs)
 }
 
 func (m *Model) handleSearchResults(msg searchResultsMsg) {
 	m.searching = false
+	if msg.incomplete {
+		return
+	}
 	if msg.err != nil {
 		m.message = "Search failed: " + msg.err.Error()
 		m.messageType = "error"
@@ -85,18 +209,1061 @@ func (m *Model) handleSearchResults(msg searchResultsMsg) {
 	}
 
 	m.packages = msg.packages
+	m.policy.Apply(m.packages)
+	search.Rank(m.packages, m.booster)
+	search.Sort(m.packages, m.sortMode)
 	m.fromCache = msg.fromCache
 	m.cursor = 0
 	m.selected = make(map[int]bool)
 
-	if len(msg.packages) == 0 {
+	switch {
+	case len(msg.packages) == 0:
 		m.message = "No packages found"
 		m.messageType = "info"
-	} else {
+	case msg.offline:
+		m.message = "Search providers unreachable, showing offline results"
+		m.messageType = "info"
+	default:
 		m.message = ""
 	}
 }
 
+// opens the version picker for the package at idx and loads its versions
+func (m *Model) openVersionPicker(idx int) tea.Cmd {
+	if idx >= len(m.packages) {
+		return nil
+	}
+
+	pkg := m.packages[idx]
+	if pkg.ModulePath == "" {
+		m.packages[idx].ModulePath = m.pkgManager.ModulePath(pkg.ImportPath)
+	}
+
+	m.viewState = ViewVersions
+	m.versionPkgIdx = idx
+	m.versionCursor = 0
+	m.versions = nil
+	m.versionTimesAsked = make(map[string]bool)
+	m.loadingVersions = true
+	m.searchInput.Blur()
+
+	modulePath := m.packages[idx].ModulePath
+	client := m.proxy
+
+	return func() tea.Msg {
+		if client == nil {
+			return versionsMsg{importPath: pkg.ImportPath, err: proxy.ErrDisabled}
+		}
+		versions, err := client.VersionHistory(modulePath)
+		return versionsMsg{importPath: pkg.ImportPath, versions: versions, err: err}
+	}
+}
+
+func (m *Model) handleVersions(msg versionsMsg) {
+	// ignore answers for a picker that was closed or reopened elsewhere
+	if m.viewState != ViewVersions {
+		return
+	}
+	if m.versionDep != nil {
+		m.handleDepVersions(msg)
+		return
+	}
+	if m.versionPkgIdx >= len(m.packages) || m.packages[m.versionPkgIdx].ImportPath != msg.importPath {
+		return
+	}
+
+	m.loadingVersions = false
+	if msg.err != nil {
+		m.closeVersionPicker()
+		m.message = "Failed to fetch versions: " + msg.err.Error()
+		m.messageType = "error"
+		return
+	}
+
+	m.versions = msg.versions
+
+	// start on the current version, or the newest non-retracted one
+	pkg := m.packages[m.versionPkgIdx]
+	for i, v := range m.versions {
+		if v.Version == pkg.RequiredVersion || (pkg.RequiredVersion == "" && !v.Retracted && !v.Pseudo) {
+			m.versionCursor = i
+			break
+		}
+	}
+}
+
+// returns the module the version picker lists versions of
+func (m *Model) versionModulePath() string {
+	if m.versionDep != nil {
+		return m.versionDep.Path
+	}
+	if m.versionPkgIdx >= len(m.packages) {
+		return ""
+	}
+	return m.packages[m.versionPkgIdx].ModulePath
+}
+
+// fetches the release times of the versions on screen that were not asked
+// for yet; a long version list would otherwise cost a request per version
+func (m *Model) fetchVersionTimes() tea.Cmd {
+	if m.viewState != ViewVersions || m.proxy == nil {
+		return nil
+	}
+
+	var pending []string
+	for _, idx := range m.getVisibleVersions() {
+		v := m.versions[idx]
+		if v.Time.IsZero() && !m.versionTimesAsked[v.Version] {
+			m.versionTimesAsked[v.Version] = true
+			pending = append(pending, v.Version)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	modulePath := m.versionModulePath()
+	client := m.proxy
+	return func() tea.Msg {
+		return versionTimesMsg{modulePath: modulePath, times: client.ReleaseTimes(modulePath, pending)}
+	}
+}
+
+func (m *Model) handleVersionTimes(msg versionTimesMsg) {
+	if m.viewState != ViewVersions || m.versionModulePath() != msg.modulePath {
+		return
+	}
+	for i, v := range m.versions {
+		if t, ok := msg.times[v.Version]; ok {
+			m.versions[i].Time = t
+		}
+	}
+}
+
+func (m *Model) closeVersionPicker() {
+	m.versions = nil
+	m.loadingVersions = false
+
+	if m.versionDep != nil {
+		m.versionDep = nil
+		m.viewState = ViewDeps
+		return
+	}
+
+	m.viewState = ViewSearch
+	m.searchInput.Focus()
+}
+
+// checks the requirements of the current module for newer versions and
+// lists them for selection like search results
+func (m *Model) openOutdated() tea.Cmd {
+	project := m.pkgManager.Project()
+	if project == nil {
+		m.message = "Not inside a Go module"
+		m.messageType = "error"
+		return nil
+	}
+
+	m.savedSearch = savedSearch{packages: m.packages, selected: m.selected, cursor: m.cursor}
+	m.packages = nil
+	m.selected = make(map[int]bool)
+	m.cursor = 0
+
+	m.viewState = ViewOutdated
+	m.updates = nil
+	m.updatesErr = nil
+	m.loadingUpdates = true
+	m.searchInput.Blur()
+
+	pm := m.pkgManager
+	mod := project.Modules[0]
+	return func() tea.Msg {
+		updates, err := pm.Outdated(mod)
+		return outdatedMsg{updates: updates, err: err}
+	}
+}
+
+func (m *Model) handleOutdated(msg outdatedMsg) {
+	if m.viewState != ViewOutdated {
+		return
+	}
+
+	m.loadingUpdates = false
+	m.updatesErr = msg.err
+	m.updates = msg.updates
+	m.packages = make([]cache.Package, len(msg.updates))
+	for i, u := range msg.updates {
+		m.packages[i] = u.Package()
+	}
+	m.policy.Apply(m.packages)
+}
+
+// switches to the next sort mode, skipping stars when no result has them,
+// and keeps the selection and cursor on the same packages
+func (m *Model) cycleSort() {
+	m.sortMode = m.sortMode.Next()
+	if m.sortMode == search.SortStars && !search.HasStars(m.packages) {
+		m.sortMode = m.sortMode.Next()
+	}
+
+	selected := make(map[string]bool)
+	for idx, ok := range m.selected {
+		if ok && idx < len(m.packages) {
+			selected[m.packages[idx].ImportPath] = true
+		}
+	}
+	current := ""
+	if m.cursor < len(m.packages) {
+		current = m.packages[m.cursor].ImportPath
+	}
+
+	search.Sort(m.packages, m.sortMode)
+
+	m.selected = make(map[int]bool)
+	for i, pkg := range m.packages {
+		if selected[pkg.ImportPath] {
+			m.selected[i] = true
+		}
+		if pkg.ImportPath == current {
+			m.cursor = i
+		}
+	}
+}
+
+// puts the search results back
+func (m *Model) closeOutdated() {
+	m.packages = m.savedSearch.packages
+	m.selected = m.savedSearch.selected
+	m.cursor = m.savedSearch.cursor
+	m.savedSearch = savedSearch{}
+	m.updates = nil
+
+	m.viewState = ViewSearch
+	m.searchInput.Focus()
+}
+
+// lists the requirements of the current module
+func (m *Model) openDeps() {
+	if m.pkgManager.Project() == nil {
+		m.message = "Not inside a Go module"
+		m.messageType = "error"
+		return
+	}
+
+	m.viewState = ViewDeps
+	m.depCursor = 0
+	m.message = ""
+	m.searchInput.Blur()
+}
+
+func (m *Model) closeDeps() {
+	m.viewState = ViewSearch
+	m.message = ""
+	m.searchInput.Focus()
+}
+
+func (m *Model) selectedDep() (packages.Requirement, bool) {
+	reqs := m.pkgManager.Project().Modules[0].Requirements()
+	if m.depCursor >= len(reqs) {
+		return packages.Requirement{}, false
+	}
+	return reqs[m.depCursor], true
+}
+
+// opens the version picker for a requirement of go.mod; picking a version
+// runs go get with it
+func (m *Model) openDepVersionPicker(req packages.Requirement) tea.Cmd {
+	m.viewState = ViewVersions
+	m.versionDep = &req
+	m.versionCursor = 0
+	m.versionTimesAsked = make(map[string]bool)
+	m.versions = nil
+	m.loadingVersions = true
+
+	client := m.proxy
+	return func() tea.Msg {
+		if client == nil {
+			return versionsMsg{importPath: req.Path, err: proxy.ErrDisabled}
+		}
+		versions, err := client.VersionHistory(req.Path)
+		return versionsMsg{importPath: req.Path, versions: versions, err: err}
+	}
+}
+
+func (m *Model) handleDepVersions(msg versionsMsg) {
+	if m.versionDep.Path != msg.importPath {
+		return
+	}
+
+	m.loadingVersions = false
+	if msg.err != nil {
+		m.closeVersionPicker()
+		m.message = "Failed to fetch versions: " + msg.err.Error()
+		m.messageType = "error"
+		return
+	}
+
+	m.versions = msg.versions
+	for i, v := range m.versions {
+		if v.Version == m.versionDep.Version {
+			m.versionCursor = i
+			break
+		}
+	}
+}
+
+// runs go get for the requirement in the installing view and returns to
+// the dependencies view afterwards. An empty version removes it
+func (m *Model) changeDependency(req packages.Requirement, version string) tea.Cmd {
+	pm := m.pkgManager
+	mod := pm.Project().Modules[0]
+
+	job := installJob{
+		pkg:          cache.Package{Name: path.Base(req.Path), ImportPath: req.Path, ModulePath: req.Path},
+		needsInstall: true,
+	}
+	if version == "" {
+		job.action = history.ActionRemoved
+		job.run = func(ctx context.Context, progress func(string)) error {
+			return pm.RemoveDependency(ctx, mod, req.Path, progress)
+		}
+	} else {
+		job.action = history.ActionUpgraded
+		if version != "latest" && semver.Compare(packages.CanonicalVersion(version), req.Version) < 0 {
+			job.action = history.ActionDowngraded
+		}
+		job.run = func(ctx context.Context, progress func(string)) error {
+			return pm.UpdateDependency(ctx, mod, req.Path, version, progress)
+		}
+	}
+
+	cmd := m.runInstallJobs([]installJob{job})
+	m.installReturn = ViewDeps
+	return cmd
+}
+
+// opens the detail view for the package at idx, fetching details unless
+// they were loaded before in this session or are in the on-disk cache
+func (m *Model) openDetails(idx int) tea.Cmd {
+	if idx >= len(m.packages) {
+		return nil
+	}
+
+	m.viewState = ViewDetails
+	m.detailsPkgIdx = idx
+	m.detailsScroll = 0
+	m.detailsErr = nil
+	m.searchInput.Blur()
+
+	importPath := m.packages[idx].ImportPath
+	if _, ok := m.details[importPath]; ok {
+		m.loadingDetails = false
+		return nil
+	}
+	m.loadingDetails = true
+
+	return func() tea.Msg {
+		if cached, found := m.cache.GetDetails(importPath); found {
+			return detailsMsg{importPath: importPath, pkg: cached}
+		}
+
+		pkg, err := m.searcher.FetchPackageDetails(importPath)
+		if err != nil {
+			return detailsMsg{importPath: importPath, err: err}
+		}
+
+		m.cache.SetDetails(*pkg)
+		return detailsMsg{importPath: importPath, pkg: pkg}
+	}
+}
+
+func (m *Model) handleDetails(msg detailsMsg) {
+	if msg.pkg != nil {
+		m.details[msg.importPath] = msg.pkg
+		if msg.pkg.Command {
+			m.markCommand(msg.importPath)
+		}
+	}
+
+	if m.viewState != ViewDetails || m.detailsPkgIdx >= len(m.packages) ||
+		m.packages[m.detailsPkgIdx].ImportPath != msg.importPath {
+		return
+	}
+
+	m.loadingDetails = false
+	m.detailsErr = msg.err
+}
+
+func (m *Model) closeDetails() {
+	m.viewState = ViewSearch
+	m.loadingDetails = false
+	m.searchInput.Focus()
+}
+
+// asks the module proxy which of pkgs are commands, so that the options
+// view can offer go install for results pkg.go.dev did not label
+func (m *Model) detectCommands(pkgs []cache.Package) tea.Cmd {
+	var unknown []cache.Package
+	for _, pkg := range pkgs {
+		if !pkg.Command {
+			unknown = append(unknown, pkg)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	pm := m.pkgManager
+	return func() tea.Msg {
+		var found []string
+		for _, pkg := range unknown {
+			if isCommand, err := pm.DetectCommand(pkg); err == nil && isCommand {
+				found = append(found, pkg.ImportPath)
+			}
+		}
+		return commandsMsg{importPaths: found}
+	}
+}
+
+func (m *Model) handleCommandsDetected(msg commandsMsg) {
+	for _, importPath := range msg.importPaths {
+		m.markCommand(importPath)
+	}
+}
+
+func (m *Model) markCommand(importPath string) {
+	for i := range m.packages {
+		if m.packages[i].ImportPath == importPath && !m.packages[i].Command {
+			m.packages[i].Command = true
+			m.packages[i].InGoBin = m.pkgManager.IsBinaryInstalled(importPath)
+		}
+	}
+}
+
+// checks the module versions that installing pkgs would add and that were not
+// checked before
+func (m *Model) checkVulns(pkgs []cache.Package) tea.Cmd {
+	if m.vulnDB == nil || m.vulnDB.Disabled() {
+		return nil
+	}
+
+	var pending []module.Version
+	for _, pkg := range pkgs {
+		mv := m.pkgManager.InstallModule(pkg)
+		if _, checked := m.vulns[mv]; mv.Version == "" || checked {
+			continue
+		}
+		pending = append(pending, mv)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	db := m.vulnDB
+	return func() tea.Msg {
+		found := make(map[module.Version][]vuln.Finding)
+		for _, mv := range pending {
+			findings, err := db.Check(mv.Path, mv.Version)
+			if err != nil {
+				return vulnsMsg{found: found, err: err}
+			}
+			found[mv] = findings
+		}
+		return vulnsMsg{found: found}
+	}
+}
+
+func (m *Model) handleVulns(msg vulnsMsg) {
+	for mv, findings := range msg.found {
+		m.vulns[mv] = findings
+	}
+	m.vulnErr = msg.err
+}
+
+func (m *Model) vulnsFor(pkg cache.Package) []vuln.Finding {
+	return m.vulns[m.pkgManager.InstallModule(pkg)]
+}
+
+// classifies the license files of selected packages that search results
+// gave no license for
+func (m *Model) detectLicenses(pkgs []cache.Package) tea.Cmd {
+	var unknown []cache.Package
+	for _, pkg := range pkgs {
+		if _, checked := m.licenses[pkg.ImportPath]; pkg.License == "" && !pkg.Stdlib && !checked {
+			unknown = append(unknown, pkg)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	pm := m.pkgManager
+	return func() tea.Msg {
+		found := make(map[string]string)
+		for _, pkg := range unknown {
+			// a failed detection is recorded too, like the CLI it leaves the
+			// license unknown rather than blocking the install
+			id, _ := pm.DetectLicense(pkg)
+			found[pkg.ImportPath] = id
+		}
+		return licensesMsg{licenses: found}
+	}
+}
+
+func (m *Model) handleLicenses(msg licensesMsg) {
+	for importPath, id := range msg.licenses {
+		m.licenses[importPath] = id
+	}
+	for i := range m.packages {
+		if id := msg.licenses[m.packages[i].ImportPath]; id != "" && m.packages[i].License == "" {
+			m.packages[i].License = id
+		}
+	}
+}
+
+// returns the license of pkg from search results or detection. Copies of
+// packages taken before detection finished still get it this way
+func (m *Model) licenseOf(pkg cache.Package) string {
+	if pkg.License != "" {
+		return pkg.License
+	}
+	return m.licenses[pkg.ImportPath]
+}
+
+// reports whether a blocking policy has to wait for the license detection of
+// some of pkgs before they may be installed
+func (m *Model) licensePending(pkgs []cache.Package) bool {
+	if !m.config.LicensePolicy.Blocks() {
+		return false
+	}
+	for _, pkg := range pkgs {
+		if _, checked := m.licenses[pkg.ImportPath]; pkg.License == "" && !pkg.Stdlib && !checked {
+			return true
+		}
+	}
+	return false
+}
+
+// describes why the license policy rejects each of pkgs that it rejects
+func (m *Model) licenseViolations(pkgs []cache.Package) []string {
+	policy := m.config.LicensePolicy
+	if !policy.Enabled() {
+		return nil
+	}
+
+	var violations []string
+	for _, pkg := range pkgs {
+		if pkg.Stdlib {
+			// ships with Go, nothing gets installed
+			continue
+		}
+		if reason := license.Check(policy, m.licenseOf(pkg)); reason != "" {
+			violations = append(violations, pkg.Name+": "+reason)
+		}
+	}
+	return violations
+}
+
+// reports whether the policy forbids installing pkgs
+func (m *Model) licenseBlocked(pkgs []cache.Package) bool {
+	return m.config.LicensePolicy.Blocks() && len(m.licenseViolations(pkgs)) > 0
+}
+
+// checks pkgs against the license policy once more right before installing,
+// since licenses may have been detected after they were chosen
+func (m *Model) refuseBlockedLicenses(pkgs []cache.Package) bool {
+	if !m.licenseBlocked(pkgs) {
+		return false
+	}
+	m.message = "Blocked by the license policy: " + strings.Join(m.licenseViolations(pkgs), ", ")
+	m.messageType = "error"
+	return true
+}
+
+// explains why installing pkgs has nothing to do
+func nothingToInstall(pkgs []cache.Package) string {
+	if !allStdlib(pkgs) {
+		return "All selected packages are already installed"
+	}
+	if len(pkgs) == 1 {
+		return pkgs[0].ImportPath + " is in the standard library, press Y to copy its import"
+	}
+	return "The selected packages are in the standard library, press Y to copy their imports"
+}
+
+// copies the import declaration of pkgs to the clipboard, or shows it when
+// there is no clipboard to copy to
+func (m *Model) copyImports(pkgs []cache.Package) {
+	var paths []string
+	for _, pkg := range pkgs {
+		paths = append(paths, pkg.ImportPath)
+	}
+	lines := packages.ImportLines(paths)
+
+	m.viewState = ViewSearch
+	m.searchInput.Focus()
+
+	if err := clipboard.WriteAll(lines); err != nil {
+		m.message = "No clipboard available, add: " + strings.Join(strings.Fields(lines), " ")
+		m.messageType = "info"
+		return
+	}
+	m.message = "Copied to the clipboard: " + strings.Join(strings.Fields(lines), " ")
+	m.messageType = "success"
+}
+
+// adds imports of pkgs to the file from $GOPICK_FILE. done is what happened
+// before, e.g. "Installed", and leads the message
+func (m *Model) insertImports(pkgs []cache.Package, done string) {
+	var specs []imports.Spec
+	for _, pkg := range pkgs {
+		specs = append(specs, imports.Spec{Path: pkg.ImportPath})
+	}
+
+	name := filepath.Base(m.importFile)
+	added, err := imports.AddToFile(m.importFile, specs, m.pkgManager.IsStdlib)
+	switch {
+	case err != nil:
+		m.message = "Failed to add imports: " + err.Error()
+		m.messageType = "error"
+		return
+	case len(added) == 0:
+		m.message = name + " already imports the selected packages"
+		m.messageType = "info"
+	default:
+		m.message = fmt.Sprintf("added %d import(s) to %s", len(added), name)
+		m.messageType = "success"
+	}
+
+	if done != "" {
+		m.message = done + "; " + m.message
+	} else {
+		m.message = strings.ToUpper(m.message[:1]) + m.message[1:]
+	}
+}
+
+func allStdlib(pkgs []cache.Package) bool {
+	for _, pkg := range pkgs {
+		if !pkg.Stdlib {
+			return false
+		}
+	}
+	return len(pkgs) > 0
+}
+
+func hasCommands(pkgs []cache.Package) bool {
+	for _, pkg := range pkgs {
+		if pkg.Command {
+			return true
+		}
+	}
+	return false
+}
+
+// returns the go.work modules picked as install targets, in workspace order
+func (m *Model) selectedModules() []packages.Module {
+	project := m.pkgManager.Project()
+	if project == nil {
+		return nil
+	}
+
+	var modules []packages.Module
+	for _, mod := range project.Modules {
+		if m.targetModules[mod.Dir] {
+			modules = append(modules, mod)
+		}
+	}
+	return modules
+}
+
+func (m *Model) installCommand(pkgs []cache.Package) string {
+	cwd, _ := os.Getwd()
+	return m.pkgManager.GetInstallCommandIn(pkgs, m.selectedModules(), cwd)
+}
+
+// shows what installing pkgs would change in go.mod before doing it. Outside
+// a module there is nothing to preview and the install starts right away
+func (m *Model) openPreview(pkgs []cache.Package) tea.Cmd {
+	project := m.pkgManager.Project()
+	if project == nil {
+		return m.startInstall(pkgs)
+	}
+
+	modules := m.selectedModules()
+	if len(modules) == 0 {
+		modules = project.Modules[:1]
+	}
+
+	m.viewState = ViewPreview
+	m.previews = nil
+	m.previewPkgs = pkgs
+	m.previewScroll = 0
+	m.loadingPreview = true
+	m.searchInput.Blur()
+
+	pm := m.pkgManager
+	return func() tea.Msg {
+		previews := make([]modulePreview, len(modules))
+		for i, mod := range modules {
+			preview, err := pm.PreviewInstallIn(mod, pkgs)
+			previews[i] = modulePreview{module: mod, preview: preview, err: err}
+		}
+		return previewMsg{previews: previews}
+	}
+}
+
+func (m *Model) handlePreview(msg previewMsg) {
+	// the preview may have been cancelled while go get was running
+	if m.viewState != ViewPreview {
+		return
+	}
+
+	m.loadingPreview = false
+	m.previews = msg.previews
+}
+
+func (m *Model) confirmPreview() tea.Cmd {
+	if m.loadingPreview {
+		return nil
+	}
+
+	pkgs, importPkgs := m.previewPkgs, m.importPkgs
+	m.closePreview()
+	if m.refuseBlockedLicenses(pkgs) {
+		return nil
+	}
+	m.importPkgs = importPkgs
+	return m.startInstall(pkgs)
+}
+
+func (m *Model) closePreview() {
+	m.viewState = ViewSearch
+	m.previews = nil
+	m.previewPkgs = nil
+	m.loadingPreview = false
+	m.importPkgs = nil
+	m.searchInput.Focus()
+}
+
+// one go command run for a package, e.g. go get in a module directory
+type installJob struct {
+	pkg          cache.Package
+	needsInstall bool
+	// shown above the job's output, e.g. the module directory
+	header string
+	// recorded in the history once run succeeded; defaults to installed
+	action history.ActionType
+	run    func(ctx context.Context, progress func(string)) error
+}
+
+// installs pkgs one by one in the background, streaming go get output into
+// the installing view through m.installCh
+func (m *Model) startInstall(pkgs []cache.Package) tea.Cmd {
+	if m.refuseBlockedLicenses(pkgs) {
+		m.importPkgs = nil
+		return nil
+	}
+	pm := m.pkgManager
+
+	var jobs []installJob
+	if modules := m.selectedModules(); len(modules) > 0 {
+		for _, mod := range modules {
+			for _, pkg := range pkgs {
+				pkg, dir := pkg, mod.Dir
+				jobs = append(jobs, installJob{
+					pkg:          pkg,
+					needsInstall: mod.NeedsInstall(pkg),
+					header:       "==> " + dir,
+					run: func(ctx context.Context, progress func(string)) error {
+						return pm.InstallPackageIn(ctx, dir, pm.InstallTarget(pkg), progress)
+					},
+				})
+			}
+		}
+	} else {
+		for _, pkg := range pkgs {
+			pkg := pkg
+			jobs = append(jobs, installJob{
+				pkg:          pkg,
+				needsInstall: packages.NeedsInstall(pkg),
+				run: func(ctx context.Context, progress func(string)) error {
+					return pm.InstallPackage(ctx, pm.InstallTarget(pkg), progress)
+				},
+			})
+		}
+	}
+
+	return m.runInstallJobs(jobs)
+}
+
+// records the commands among pkgs as tools of the current module with go get -tool
+func (m *Model) startAddTools(pkgs []cache.Package) tea.Cmd {
+	pm := m.pkgManager
+	mod := pm.Project().Modules[0]
+
+	var jobs []installJob
+	for _, pkg := range pkgs {
+		if !pkg.Command {
+			continue
+		}
+		pkg := pkg
+		jobs = append(jobs, installJob{
+			pkg:          pkg,
+			needsInstall: !pkg.Tool || pkg.Pinned,
+			run: func(ctx context.Context, progress func(string)) error {
+				return pm.AddTool(ctx, mod, packages.ToolDirectiveTarget(pkg), progress)
+			},
+		})
+	}
+
+	return m.runInstallJobs(jobs)
+}
+
+func (m *Model) runInstallJobs(jobs []installJob) tea.Cmd {
+	m.installReturn = ViewSearch
+	m.viewState = ViewInstalling
+	m.installing = true
+	m.installProgress = 0
+	m.installMessage = ""
+	m.installLog = nil
+	m.installFailures = nil
+	m.searchInput.Blur()
+
+	ch := make(chan tea.Msg, 64)
+	m.installCh = ch
+	ctx, cancel := context.WithCancel(context.Background())
+	m.installCancel = cancel
+	pm := m.pkgManager
+
+	go func() {
+		// once cancelled nobody reads ch anymore; closing it ends the
+		// waitForInstall still pending
+		defer close(ch)
+		send := func(msg tea.Msg) {
+			select {
+			case ch <- msg:
+			case <-ctx.Done():
+			}
+		}
+
+		total := float64(len(jobs))
+		for i, job := range jobs {
+			if ctx.Err() != nil {
+				break
+			}
+			percent := float64(i) / total * 100
+			pkg := job.pkg
+
+			if !job.needsInstall {
+				send(installProgressMsg{
+					percent: float64(i+1) / total * 100,
+					message: fmt.Sprintf("✓ %s already installed", pkg.ImportPath),
+				})
+				continue
+			}
+
+			if job.header != "" {
+				send(installProgressMsg{percent: percent, message: job.header})
+			}
+			err := job.run(ctx, func(line string) {
+				send(installProgressMsg{percent: percent, message: line})
+			})
+			if ctx.Err() != nil {
+				break
+			}
+			if err != nil {
+				send(installErrorMsg{pkg: pkg, err: err})
+				continue
+			}
+
+			installed := pkg
+			send(installProgressMsg{percent: float64(i+1) / total * 100, installed: &installed, action: job.action})
+		}
+
+		pm.RefreshCache()
+		send(installProgressMsg{percent: 100, done: true})
+	}()
+
+	return waitForInstall(ch)
+}
+
+// lists the tool directives of the current module
+func (m *Model) openTools() {
+	if m.pkgManager.Project() == nil {
+		m.message = "Not inside a Go module"
+		m.messageType = "error"
+		return
+	}
+
+	m.viewState = ViewTools
+	m.toolCursor = 0
+	m.searchInput.Blur()
+}
+
+func (m *Model) closeTools() {
+	m.viewState = ViewSearch
+	m.searchInput.Focus()
+}
+
+func (m *Model) removeTool() tea.Cmd {
+	tools := m.pkgManager.Project().Modules[0].Tools
+	if m.toolCursor >= len(tools) {
+		return nil
+	}
+
+	tool := tools[m.toolCursor]
+	if err := m.pkgManager.RemoveTool(m.pkgManager.Project().Modules[0], tool); err != nil {
+		m.message = fmt.Sprintf("Failed to remove %s: %v", tool, err)
+		m.messageType = "error"
+		return nil
+	}
+
+	m.message = "Removed tool " + tool
+	m.messageType = "success"
+	if m.toolCursor > 0 && m.toolCursor >= len(tools)-1 {
+		m.toolCursor--
+	}
+	return m.refreshInstalled()
+}
+
+// marks the install status of the results again off the UI goroutine, as
+// it runs go list -m for each of them
+func (m *Model) refreshInstalled() tea.Cmd {
+	pkgs := append([]cache.Package(nil), m.packages...)
+	pm := m.pkgManager
+	return func() tea.Msg {
+		return installedMsg{packages: pm.MarkInstalledPackages(pkgs)}
+	}
+}
+
+// copies the refreshed install status onto the results by import path,
+// since they may have been sorted, pinned or replaced meanwhile
+func (m *Model) handleInstalled(msg installedMsg) {
+	marked := make(map[string]cache.Package, len(msg.packages))
+	for _, pkg := range msg.packages {
+		marked[pkg.ImportPath] = pkg
+	}
+
+	for i := range m.packages {
+		fresh, ok := marked[m.packages[i].ImportPath]
+		if !ok {
+			continue
+		}
+		pkg := &m.packages[i]
+		pkg.ModulePath = fresh.ModulePath
+		pkg.Stdlib = fresh.Stdlib
+		pkg.IsInstalled = fresh.IsInstalled
+		pkg.InModCache = fresh.InModCache
+		pkg.InModule = fresh.InModule
+		pkg.RequiredVersion = fresh.RequiredVersion
+		pkg.Indirect = fresh.Indirect
+		pkg.Command = fresh.Command
+		pkg.InGoBin = fresh.InGoBin
+		pkg.Tool = fresh.Tool
+	}
+}
+
+func waitForInstall(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
+func (m *Model) handleInstallProgress(msg installProgressMsg) tea.Cmd {
+	// left over from a cancelled install
+	if !m.installing {
+		return nil
+	}
+	m.installProgress = msg.percent
+	if msg.message != "" {
+		m.installMessage = msg.message
+		m.appendInstallLog(msg.message)
+	}
+
+	if msg.installed != nil {
+		action := msg.action
+		if action == "" {
+			action = history.ActionInstalled
+		}
+		m.history.Add(msg.installed.Name, msg.installed.ImportPath, action)
+		m.installedPkgs[msg.installed.ImportPath] = action != history.ActionRemoved
+	}
+
+	if !msg.done {
+		return waitForInstall(m.installCh)
+	}
+
+	m.installing = false
+	m.installCh = nil
+	m.installCancel = nil
+	m.selected = make(map[int]bool)
+	importPkgs := m.importPkgs
+	m.importPkgs = nil
+
+	if len(m.installFailures) == 0 {
+		m.closeInstall()
+		m.message = "Installation completed successfully!"
+		if m.viewState == ViewDeps {
+			m.message = "go.mod updated"
+		}
+		m.messageType = "success"
+		if len(importPkgs) > 0 {
+			m.insertImports(importPkgs, "Installed")
+		}
+		return m.refreshInstalled()
+	}
+
+	// stay on the installing view so the failures can be read
+	m.installMessage = fmt.Sprintf("%d package(s) failed to install", len(m.installFailures))
+	return m.refreshInstalled()
+}
+
+func (m *Model) handleInstallError(msg installErrorMsg) tea.Cmd {
+	if !m.installing {
+		return nil
+	}
+	m.installFailures = append(m.installFailures, installFailure{pkg: msg.pkg, err: msg.err})
+	m.appendInstallLog(fmt.Sprintf("✗ %s failed", msg.pkg.ImportPath))
+	return waitForInstall(m.installCh)
+}
+
+// stops the running install and goes back to where it was started from.
+// What finished before stays installed
+func (m *Model) cancelInstall() tea.Cmd {
+	m.installCancel()
+	m.installCancel = nil
+	m.installing = false
+	m.installCh = nil
+	m.installFailures = nil
+	m.importPkgs = nil
+
+	m.closeInstall()
+	m.message = "Installation cancelled"
+	m.messageType = "error"
+	return m.refreshInstalled()
+}
+
+func (m *Model) appendInstallLog(line string) {
+	m.installLog = append(m.installLog, line)
+	if len(m.installLog) > maxInstallLog {
+		m.installLog = m.installLog[len(m.installLog)-maxInstallLog:]
+	}
+}
+
+func (m *Model) closeInstall() {
+	if len(m.installFailures) > 0 {
+		m.message = fmt.Sprintf("%d package(s) failed to install", len(m.installFailures))
+		m.messageType = "error"
+	}
+
+	m.viewState = m.installReturn
+	m.installFailures = nil
+	m.installLog = nil
+	if m.viewState == ViewSearch {
+		m.searchInput.Focus()
+	}
+}
+
 func ShowMessage(message, messageType string) tea.Cmd {
 	return func() tea.Msg {
 		return struct {
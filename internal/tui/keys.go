@@ -2,19 +2,24 @@ package tui
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/MdSadiqMd/gopick/internal/cache"
 	"github.com/MdSadiqMd/gopick/internal/history"
+	"github.com/MdSadiqMd/gopick/internal/policy"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func (m *Model) handleSearchKeys(msg tea.KeyMsg) tea.Cmd {
+// handles msg in the search view and reports whether it was a command, which
+// the search input must not see
+func (m *Model) handleSearchKeys(msg tea.KeyMsg) (tea.Cmd, bool) {
 	switch msg.Type {
 	case tea.KeyCtrlC:
-		return tea.Quit
+		return tea.Quit, true
 
 	case tea.KeyEsc:
 		if m.searchInput.Value() == "" {
-			return tea.Quit
+			return tea.Quit, true
 		}
 		// clear search
 		m.searchInput.SetValue("")
@@ -22,30 +27,30 @@ func (m *Model) handleSearchKeys(msg tea.KeyMsg) tea.Cmd {
 		m.packages = nil
 		m.message = ""
 		m.cursor = 0
-		return nil
+		return nil, true
 
 	case tea.KeyUp:
 		if len(m.packages) > 0 && m.cursor > 0 {
 			m.cursor--
 		}
-		return nil
+		return nil, true
 
 	case tea.KeyDown:
 		if len(m.packages) > 0 && m.cursor < len(m.packages)-1 {
 			m.cursor++
 		}
-		return nil
+		return nil, true
 
 	case tea.KeyTab:
 		if len(m.packages) > 0 && m.cursor < len(m.packages) {
 			m.selected[m.cursor] = !m.selected[m.cursor]
 		}
-		return nil
+		return nil, true
 
 	case tea.KeyEnter:
 		if m.firstRun {
 			m.firstRun = false
-			return nil
+			return nil, true
 		}
 
 		selected := m.getSelectedPackages()
@@ -60,68 +65,83 @@ func (m *Model) handleSearchKeys(msg tea.KeyMsg) tea.Cmd {
 			for _, pkg := range selected {
 				m.history.Add(pkg.Name, pkg.ImportPath, history.ActionViewed)
 			}
+			return tea.Batch(m.detectCommands(selected), m.detectLicenses(selected)), true
 		}
-		return nil
+		return nil, true
 
 	case tea.KeyCtrlH:
 		m.showHelp = !m.showHelp
-		return nil
+		return nil, true
 
 	case tea.KeyCtrlQ:
-		return tea.Quit
+		return tea.Quit, true
 
 	case tea.KeyCtrlA:
-		if len(m.packages) > 0 {
-			for i := range m.packages {
-				m.selected[i] = true
-			}
-		}
-		return nil
+		m.selectAll()
+		return nil, true
 
 	case tea.KeyCtrlN:
 		m.selected = make(map[int]bool)
-		return nil
+		return nil, true
 
-	case tea.KeyRunes:
-		// shift+letter commands
-		runes := msg.Runes
-		if len(runes) == 1 {
-			switch runes[0] {
-			case 'Q':
-				return tea.Quit
-			case 'H':
-				m.showHelp = !m.showHelp
-				return nil
-			case 'A':
-				if len(m.packages) > 0 {
-					for i := range m.packages {
-						m.selected[i] = true
-					}
-				}
-				return nil
-			case 'N':
-				m.selected = make(map[int]bool)
-				return nil
-			case 'C':
-				if err := m.cache.Clear(); err == nil {
-					m.message = "Cache cleared successfully"
-					m.messageType = "success"
-				} else {
-					m.message = fmt.Sprintf("Failed to clear cache: %v", err)
-					m.messageType = "error"
-				}
-				return nil
+	case tea.KeyRunes, tea.KeyF1, tea.KeyF2, tea.KeyF3, tea.KeyF4, tea.KeyF5, tea.KeyF6, tea.KeyF7, tea.KeyF8:
+		// letters, capitals included, belong to the query. Commands are alt
+		// chords, with function keys for terminals that keep Option to
+		// themselves, like macOS Terminal without "Use Option as Meta"
+		if msg.Type == tea.KeyRunes && !msg.Alt {
+			return nil, false
+		}
+		switch msg.String() {
+		case "alt+q":
+			return tea.Quit, true
+		case "alt+h", "f1":
+			m.showHelp = !m.showHelp
+			return nil, true
+		case "alt+a":
+			m.selectAll()
+			return nil, true
+		case "alt+n":
+			m.selected = make(map[int]bool)
+			return nil, true
+		case "alt+v", "f2":
+			return m.openVersionPicker(m.cursor), true
+		case "alt+i", "f3":
+			return m.openDetails(m.cursor), true
+		case "alt+t", "f4":
+			m.openTools()
+			return nil, true
+		case "alt+m", "f5":
+			m.openDeps()
+			return nil, true
+		case "alt+o", "f6":
+			return m.openOutdated(), true
+		case "alt+s", "f7":
+			m.cycleSort()
+			return nil, true
+		case "alt+c", "f8":
+			if err := m.cache.Clear(); err == nil {
+				m.message = "Cache cleared successfully"
+				m.messageType = "success"
+			} else {
+				m.message = fmt.Sprintf("Failed to clear cache: %v", err)
+				m.messageType = "error"
 			}
+			return nil, true
 		}
-
-		return nil
+		return nil, false
 	}
 
 	if m.message != "" && msg.String() != "" {
 		m.message = ""
 	}
 
-	return nil
+	return nil, false
+}
+
+func (m *Model) selectAll() {
+	for i := range m.packages {
+		m.selected[i] = true
+	}
 }
 
 func (m *Model) handleOptionsKeys(msg tea.KeyMsg) tea.Cmd {
@@ -132,35 +152,96 @@ func (m *Model) handleOptionsKeys(msg tea.KeyMsg) tea.Cmd {
 		return nil
 
 	case tea.KeyRunes:
+		switch string(msg.Runes) {
+		case "g", "G", "d", "D", "f", "F", "t", "T", "p", "P":
+			if selected := m.getSelectedPackages(); m.licenseBlocked(selected) || m.licensePending(selected) {
+				// the dialog lists the licenses at fault, or says they are
+				// still being checked
+				return nil
+			}
+		}
+
 		switch string(msg.Runes) {
 		case "g", "G":
 			selected := m.getSelectedPackages()
-			command := m.pkgManager.GetInstallCommand(selected)
+			if len(policy.Banned(selected)) > 0 {
+				// a printed command is as good as installed; the dialog
+				// explains why and offers [F]
+				return nil
+			}
+			command := m.installCommand(selected)
 			if command != "" {
 				m.quitWithCommands = true
 				m.commandsToPrint = []string{command}
 				m.autoRun = false
 				return tea.Quit
 			} else {
-				m.message = "All selected packages are already installed"
+				m.message = nothingToInstall(selected)
 				m.messageType = "info"
 				m.viewState = ViewSearch
 			}
 			return nil
 
-		case "d", "D":
+		case "d", "D", "f", "F":
 			selected := m.getSelectedPackages()
-			command := m.pkgManager.GetInstallCommand(selected)
-			if command != "" {
-				m.quitWithCommands = true
-				m.commandsToPrint = []string{command}
-				m.autoRun = true
+			if len(policy.Banned(selected)) > 0 && !strings.EqualFold(string(msg.Runes), "f") {
+				// the dialog explains why and offers [F]
+				return nil
+			}
+			if m.installCommand(selected) == "" {
+				m.message = nothingToInstall(selected)
+				m.messageType = "info"
+				m.viewState = ViewSearch
+				m.searchInput.Focus()
+				return nil
+			}
+			if m.importFile != "" {
+				m.importPkgs = selected
+			}
+			return m.openPreview(selected)
 
-				for _, pkg := range selected {
-					m.history.Add(pkg.Name, pkg.ImportPath, history.ActionInstalled)
-				}
+		case "t", "T":
+			selected := m.getSelectedPackages()
+			if !hasCommands(selected) || len(policy.Banned(selected)) > 0 {
+				return nil
+			}
+			command := m.pkgManager.GetToolInstallCommand(selected)
+			if command == "" {
+				m.message = "All selected tools are already in " + m.pkgManager.GoBin()
+				m.messageType = "info"
+				m.viewState = ViewSearch
+				m.searchInput.Focus()
+				return nil
+			}
+			m.quitWithCommands = true
+			m.commandsToPrint = []string{command}
+			m.autoRun = false
+			return tea.Quit
 
-				return tea.Quit
+		case "p", "P":
+			selected := m.getSelectedPackages()
+			if !hasCommands(selected) || m.pkgManager.Project() == nil || !m.pkgManager.SupportsToolDirective() {
+				return nil
+			}
+			return m.startAddTools(selected)
+
+		case "y", "Y":
+			m.copyImports(m.getSelectedPackages())
+			return nil
+
+		case "i", "I":
+			if m.importFile == "" {
+				return nil
+			}
+			m.viewState = ViewSearch
+			m.searchInput.Focus()
+			m.insertImports(m.getSelectedPackages(), "")
+			return nil
+
+		case "m", "M":
+			if project := m.pkgManager.Project(); project != nil && len(project.Modules) > 1 {
+				m.viewState = ViewModules
+				m.moduleCursor = 0
 			}
 			return nil
 
@@ -174,6 +255,177 @@ func (m *Model) handleOptionsKeys(msg tea.KeyMsg) tea.Cmd {
 	return nil
 }
 
+func (m *Model) handleModulesKeys(msg tea.KeyMsg) {
+	modules := m.pkgManager.Project().Modules
+
+	switch msg.Type {
+	case tea.KeyUp:
+		if m.moduleCursor > 0 {
+			m.moduleCursor--
+		}
+	case tea.KeyDown:
+		if m.moduleCursor < len(modules)-1 {
+			m.moduleCursor++
+		}
+	case tea.KeyTab, tea.KeySpace:
+		dir := modules[m.moduleCursor].Dir
+		m.targetModules[dir] = !m.targetModules[dir]
+	case tea.KeyEnter, tea.KeyEsc:
+		m.viewState = ViewOptions
+	}
+}
+
+func (m *Model) handleToolsKeys(msg tea.KeyMsg) tea.Cmd {
+	tools := m.pkgManager.Project().Modules[0].Tools
+
+	switch msg.Type {
+	case tea.KeyUp:
+		if m.toolCursor > 0 {
+			m.toolCursor--
+		}
+	case tea.KeyDown:
+		if m.toolCursor < len(tools)-1 {
+			m.toolCursor++
+		}
+	case tea.KeyDelete:
+		return m.removeTool()
+	case tea.KeyEsc:
+		m.message = ""
+		m.closeTools()
+	case tea.KeyRunes:
+		switch string(msg.Runes) {
+		case "x", "X", "d", "D":
+			return m.removeTool()
+		case "q", "Q":
+			m.message = ""
+			m.closeTools()
+		}
+	}
+	return nil
+}
+
+func (m *Model) handleDepsKeys(msg tea.KeyMsg) tea.Cmd {
+	reqs := m.pkgManager.Project().Modules[0].Requirements()
+
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return tea.Quit
+	case tea.KeyUp:
+		if m.depCursor > 0 {
+			m.depCursor--
+		}
+	case tea.KeyDown:
+		if m.depCursor < len(reqs)-1 {
+			m.depCursor++
+		}
+	case tea.KeyEsc:
+		m.closeDeps()
+	case tea.KeyDelete:
+		if req, ok := m.selectedDep(); ok {
+			return m.changeDependency(req, "")
+		}
+	case tea.KeyRunes:
+		req, ok := m.selectedDep()
+		switch string(msg.Runes) {
+		case "x", "X":
+			if ok {
+				return m.changeDependency(req, "")
+			}
+		case "u", "U":
+			if ok {
+				return m.changeDependency(req, "latest")
+			}
+		case "d", "D":
+			if ok {
+				return m.openDepVersionPicker(req)
+			}
+		case "q", "Q":
+			m.closeDeps()
+		}
+	}
+
+	return nil
+}
+
+func (m *Model) handleOutdatedKeys(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return tea.Quit
+
+	case tea.KeyEsc:
+		m.closeOutdated()
+		return nil
+
+	case tea.KeyUp:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return nil
+
+	case tea.KeyDown:
+		if m.cursor < len(m.packages)-1 {
+			m.cursor++
+		}
+		return nil
+
+	case tea.KeyTab, tea.KeySpace:
+		if m.cursor < len(m.packages) {
+			m.selected[m.cursor] = !m.selected[m.cursor]
+		}
+		return nil
+
+	case tea.KeyCtrlA:
+		for i := range m.packages {
+			m.selected[i] = true
+		}
+		return nil
+
+	case tea.KeyCtrlN:
+		m.selected = make(map[int]bool)
+		return nil
+
+	case tea.KeyEnter:
+		selected := m.outdatedSelection()
+		if len(selected) == 0 {
+			return nil
+		}
+		m.closeOutdated()
+		return m.openPreview(selected)
+
+	case tea.KeyRunes:
+		switch string(msg.Runes) {
+		case "A":
+			for i := range m.packages {
+				m.selected[i] = true
+			}
+		case "N":
+			m.selected = make(map[int]bool)
+		case "g", "G":
+			command := m.pkgManager.GetInstallCommand(m.outdatedSelection())
+			if command == "" {
+				return nil
+			}
+			m.quitWithCommands = true
+			m.commandsToPrint = []string{command}
+			m.autoRun = false
+			return tea.Quit
+		case "q", "Q":
+			m.closeOutdated()
+		}
+	}
+
+	return nil
+}
+
+// the selected updates, or the one under the cursor
+func (m *Model) outdatedSelection() []cache.Package {
+	selected := m.getSelectedPackages()
+	if len(selected) == 0 && m.cursor < len(m.packages) {
+		selected = []cache.Package{m.packages[m.cursor]}
+	}
+	return selected
+}
+
 func (m *Model) handleCommandsKeys(msg tea.KeyMsg) tea.Cmd {
 	switch msg.Type {
 	case tea.KeyEsc, tea.KeyEnter:
@@ -194,3 +446,152 @@ func (m *Model) handleCommandsKeys(msg tea.KeyMsg) tea.Cmd {
 
 	return nil
 }
+
+func (m *Model) handleVersionsKeys(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return tea.Quit
+
+	case tea.KeyEsc:
+		m.closeVersionPicker()
+		return nil
+
+	case tea.KeyUp:
+		if m.versionCursor > 0 {
+			m.versionCursor--
+		}
+		return m.fetchVersionTimes()
+
+	case tea.KeyDown:
+		if m.versionCursor < len(m.versions)-1 {
+			m.versionCursor++
+		}
+		return m.fetchVersionTimes()
+
+	case tea.KeyEnter:
+		if m.loadingVersions || m.versionCursor >= len(m.versions) {
+			return nil
+		}
+
+		v := m.versions[m.versionCursor]
+		if dep := m.versionDep; dep != nil {
+			m.closeVersionPicker()
+			if v.Version == dep.Version {
+				return nil
+			}
+			return m.changeDependency(*dep, v.Version)
+		}
+
+		pkg := &m.packages[m.versionPkgIdx]
+		pkg.Version = v.Version
+		pkg.Pinned = true
+		m.selected[m.versionPkgIdx] = true
+
+		m.closeVersionPicker()
+		m.message = fmt.Sprintf("Pinned %s@%s", pkg.ModulePath, v.Version)
+		m.messageType = "success"
+		if v.Retracted {
+			m.message += " (retracted!)"
+			m.messageType = "error"
+		}
+		return m.checkVulns([]cache.Package{*pkg})
+	}
+
+	return nil
+}
+
+func (m *Model) handleDetailsKeys(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return tea.Quit
+
+	case tea.KeyEsc, tea.KeyLeft:
+		m.closeDetails()
+		return nil
+
+	case tea.KeyUp:
+		if m.detailsScroll > 0 {
+			m.detailsScroll--
+		}
+		return nil
+
+	case tea.KeyDown:
+		if details := m.details[m.packages[m.detailsPkgIdx].ImportPath]; details != nil {
+			if m.detailsScroll < len(m.readmeLines(details.Readme, m.width-8))-1 {
+				m.detailsScroll++
+			}
+		}
+		return nil
+
+	case tea.KeyTab:
+		m.selected[m.detailsPkgIdx] = !m.selected[m.detailsPkgIdx]
+		return nil
+
+	case tea.KeyRunes:
+		switch string(msg.Runes) {
+		case "V":
+			return m.openVersionPicker(m.detailsPkgIdx)
+		case "q", "Q":
+			m.closeDetails()
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (m *Model) handleInstallingKeys(msg tea.KeyMsg) tea.Cmd {
+	// the running go command can only be cancelled, the summary closed
+	if m.installing {
+		if msg.Type == tea.KeyCtrlC {
+			return m.cancelInstall()
+		}
+		return nil
+	}
+
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return tea.Quit
+	case tea.KeyEsc, tea.KeyEnter:
+		m.closeInstall()
+	}
+
+	return nil
+}
+
+func (m *Model) handlePreviewKeys(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return tea.Quit
+
+	case tea.KeyEsc:
+		m.closePreview()
+		return nil
+
+	case tea.KeyUp:
+		if m.previewScroll > 0 {
+			m.previewScroll--
+		}
+		return nil
+
+	case tea.KeyDown:
+		if m.previewScroll < len(m.previewLines())-1 {
+			m.previewScroll++
+		}
+		return nil
+
+	case tea.KeyEnter:
+		return m.confirmPreview()
+
+	case tea.KeyRunes:
+		switch string(msg.Runes) {
+		case "y", "Y":
+			return m.confirmPreview()
+		case "n", "N", "q", "Q":
+			m.closePreview()
+			return nil
+		}
+	}
+
+	return nil
+}
@@ -1,7 +1,10 @@
 package tui
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -9,14 +12,22 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/mod/module"
 
 	"github.com/MdSadiqMd/gopick/internal/cache"
 	"github.com/MdSadiqMd/gopick/internal/config"
 	"github.com/MdSadiqMd/gopick/internal/history"
+	"github.com/MdSadiqMd/gopick/internal/imports"
 	"github.com/MdSadiqMd/gopick/internal/packages"
-	"github.com/MdSadiqMd/gopick/internal/scraper"
+	"github.com/MdSadiqMd/gopick/internal/policy"
+	"github.com/MdSadiqMd/gopick/internal/proxy"
+	"github.com/MdSadiqMd/gopick/internal/search"
+	"github.com/MdSadiqMd/gopick/internal/vuln"
 )
 
+// lines of go get output kept for the installing view
+const maxInstallLog = 200
+
 type ViewState int
 
 const (
@@ -25,14 +36,24 @@ const (
 	ViewInstalling
 	ViewCommands
 	ViewHelp
+	ViewVersions
+	ViewDetails
+	ViewPreview
+	ViewModules
+	ViewTools
+	ViewDeps
+	ViewOutdated
 )
 
 type Model struct {
 	config     *config.Config
 	cache      *cache.Cache
 	history    *history.History
-	scraper    *scraper.Scraper
+	searcher   search.Searcher
 	pkgManager *packages.Manager
+	proxy      *proxy.Client
+	vulnDB     *vuln.Client
+	policy     *policy.Policy
 
 	viewState   ViewState
 	searchInput textinput.Model
@@ -46,15 +67,76 @@ type Model struct {
 	searchDebounce *time.Timer
 	lastQuery      string
 	fromCache      bool
+	sortMode       search.SortMode
+	booster        *search.Booster
 
 	installing      bool
 	installProgress float64
 	installMessage  string
-	spinner         spinner.Model
+	installLog      []string
+	installFailures []installFailure
+	installCh       chan tea.Msg
+	// stops the running go command and skips the remaining jobs
+	installCancel context.CancelFunc
+	// the view to go back to once the install finished
+	installReturn ViewState
+	spinner       spinner.Model
 
 	showHelp bool
 	commands []string
 
+	// go.work modules picked as install targets, keyed by directory; none
+	// means the module gopick was started in
+	targetModules map[string]bool
+	moduleCursor  int
+
+	toolCursor int
+	depCursor  int
+
+	// the outdated report reuses packages/selected/cursor; the search
+	// results are put aside until it is closed
+	updates        []packages.Update
+	loadingUpdates bool
+	updatesErr     error
+	savedSearch    savedSearch
+
+	versions        []proxy.Version
+	versionCursor   int
+	versionPkgIdx   int
+	loadingVersions bool
+	// versions whose release time was asked for, fetched as they scroll into view
+	versionTimesAsked map[string]bool
+	// set when the picker switches a go.mod requirement instead of pinning a result
+	versionDep *packages.Requirement
+
+	// search results that arrived while another view was open
+	pendingResults *searchResultsMsg
+
+	// findings of the vulnerability database per module version to install
+	vulns   map[module.Version][]vuln.Finding
+	vulnErr error
+	// licenses classified from module zips by import path; "" when detection
+	// failed. A missing entry means the detection has not finished
+	licenses map[string]string
+
+	// package details are fetched on demand and kept for the session
+	details        map[string]*cache.Package
+	detailsPkgIdx  int
+	detailsScroll  int
+	loadingDetails bool
+	detailsErr     error
+
+	// dry run of go get shown before installing, one per target module
+	previews       []modulePreview
+	previewPkgs    []cache.Package
+	previewScroll  int
+	loadingPreview bool
+
+	// Go file from $GOPICK_FILE that picked packages are imported into, and
+	// the packages to import once the running install succeeds
+	importFile string
+	importPkgs []cache.Package
+
 	width  int
 	height int
 
@@ -67,7 +149,7 @@ type Model struct {
 	autoRun          bool
 }
 
-func New(cfg *config.Config, c *cache.Cache, h *history.History, pm *packages.Manager) *Model {
+func New(cfg *config.Config, c *cache.Cache, h *history.History, pm *packages.Manager, s search.Searcher, pc *proxy.Client, vc *vuln.Client, pol *policy.Policy) *Model {
 	ti := textinput.New()
 	ti.Placeholder = "Search for Go packages..."
 	ti.Focus()
@@ -76,18 +158,17 @@ func New(cfg *config.Config, c *cache.Cache, h *history.History, pm *packages.Ma
 	ti.PlaceholderStyle = lipgloss.NewStyle().Foreground(dimmedColor)
 	ti.TextStyle = lipgloss.NewStyle().Foreground(fgColor)
 
-	s := spinner.New()
-	s.Spinner = spinner.Dot
-	s.Style = spinnerStyle
+	sp := spinner.New()
+	sp.Spinner = spinner.Dot
+	sp.Style = spinnerStyle
 
 	firstRun := false
 
 	installedPkgs := make(map[string]bool)
-	if allHistory, err := h.GetAll(); err == nil {
-		for _, entry := range allHistory {
-			if entry.Action == history.ActionInstalled {
-				installedPkgs[entry.ImportPath] = true
-			}
+	allHistory, _ := h.GetAll()
+	for _, entry := range allHistory {
+		if entry.Action == history.ActionInstalled {
+			installedPkgs[entry.ImportPath] = true
 		}
 	}
 
@@ -95,16 +176,25 @@ func New(cfg *config.Config, c *cache.Cache, h *history.History, pm *packages.Ma
 		config:        cfg,
 		cache:         c,
 		history:       h,
-		scraper:       scraper.New(),
+		searcher:      s,
 		pkgManager:    pm,
+		proxy:         pc,
+		vulnDB:        vc,
+		policy:        pol,
 		viewState:     ViewSearch,
 		searchInput:   ti,
 		selected:      make(map[int]bool),
-		spinner:       s,
+		details:       make(map[string]*cache.Package),
+		vulns:         make(map[module.Version][]vuln.Finding),
+		licenses:      make(map[string]string),
+		targetModules: make(map[string]bool),
+		spinner:       sp,
 		firstRun:      firstRun,
 		width:         80,
 		height:        24,
 		installedPkgs: installedPkgs,
+		booster:       search.NewBooster(allHistory, cfg.TeamGoMods),
+		importFile:    os.Getenv(imports.FileEnv),
 	}
 }
 
@@ -121,6 +211,8 @@ func (m *Model) Init() tea.Cmd {
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
+	// set when a key was a command rather than input for the search box
+	handled := false
 
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
@@ -136,7 +228,8 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-			cmd := m.handleSearchKeys(msg)
+			var cmd tea.Cmd
+			cmd, handled = m.handleSearchKeys(msg)
 			if cmd != nil {
 				cmds = append(cmds, cmd)
 			}
@@ -150,34 +243,103 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if cmd != nil {
 				cmds = append(cmds, cmd)
 			}
+		case ViewVersions:
+			cmd := m.handleVersionsKeys(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		case ViewDetails:
+			cmd := m.handleDetailsKeys(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		case ViewPreview:
+			cmd := m.handlePreviewKeys(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		case ViewModules:
+			m.handleModulesKeys(msg)
+		case ViewTools:
+			cmd := m.handleToolsKeys(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		case ViewDeps:
+			cmd := m.handleDepsKeys(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		case ViewOutdated:
+			cmd := m.handleOutdatedKeys(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
 		case ViewInstalling:
-			// No key handling during installation
+			cmd := m.handleInstallingKeys(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
 		}
 
 	case searchResultsMsg:
-		m.handleSearchResults(msg)
+		switch {
+		case msg.query != m.searchInput.Value():
+			// the query changed since, its own search is on the way
+		case m.viewState != ViewSearch:
+			// other views index m.packages by position, so the results wait
+			// until the search view is back
+			m.pendingResults = &msg
+		default:
+			m.pendingResults = nil
+			m.handleSearchResults(msg)
+			if cmd := m.checkVulns(m.packages); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+
+	case versionsMsg:
+		m.handleVersions(msg)
+		if cmd := m.fetchVersionTimes(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case versionTimesMsg:
+		m.handleVersionTimes(msg)
+
+	case detailsMsg:
+		m.handleDetails(msg)
+
+	case previewMsg:
+		m.handlePreview(msg)
+
+	case commandsMsg:
+		m.handleCommandsDetected(msg)
+
+	case outdatedMsg:
+		m.handleOutdated(msg)
+		if cmd := m.checkVulns(m.packages); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case vulnsMsg:
+		m.handleVulns(msg)
+
+	case licensesMsg:
+		m.handleLicenses(msg)
 
 	case installProgressMsg:
-		m.installProgress = msg.percent
-		m.installMessage = msg.message
-		if msg.done {
-			m.viewState = ViewSearch
-			m.installing = false
-			m.message = "Installation completed successfully!"
-			m.messageType = "success"
-			// Clear selected packages
-			m.selected = make(map[int]bool)
-			// Refresh installed status
-			m.packages = m.pkgManager.MarkInstalledPackages(m.packages)
-			// Re-focus search input
-			m.searchInput.Focus()
+		if cmd := m.handleInstallProgress(msg); cmd != nil {
+			cmds = append(cmds, cmd)
 		}
 
+	case installedMsg:
+		m.handleInstalled(msg)
+
 	case installErrorMsg:
-		m.viewState = ViewSearch
-		m.installing = false
-		m.message = fmt.Sprintf("Installation failed: %s", msg.err)
-		m.messageType = "error"
+		if cmd := m.handleInstallError(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 
 	case spinner.TickMsg:
 		var cmd tea.Cmd
@@ -185,7 +347,17 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	}
 
-	if m.viewState == ViewSearch && !m.showHelp && !m.installing {
+	if m.viewState == ViewSearch && m.pendingResults != nil {
+		if m.pendingResults.query == m.searchInput.Value() {
+			m.handleSearchResults(*m.pendingResults)
+			if cmd := m.checkVulns(m.packages); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+		m.pendingResults = nil
+	}
+
+	if m.viewState == ViewSearch && !m.showHelp && !m.installing && !handled {
 		var cmd tea.Cmd
 		oldValue := m.searchInput.Value()
 		m.searchInput, cmd = m.searchInput.Update(msg)
@@ -219,6 +391,20 @@ func (m *Model) View() string {
 		return m.renderCommands()
 	case ViewOptions:
 		return m.renderOptions()
+	case ViewVersions:
+		return m.renderVersions()
+	case ViewDetails:
+		return m.renderDetails()
+	case ViewPreview:
+		return m.renderPreview()
+	case ViewModules:
+		return m.renderModules()
+	case ViewTools:
+		return m.renderTools()
+	case ViewDeps:
+		return m.renderDeps()
+	case ViewOutdated:
+		return m.renderOutdated()
 	default:
 		if m.showHelp {
 			return m.renderHelp()
@@ -270,7 +456,8 @@ func (m *Model) renderSearch() string {
 
 	// Results header
 	if len(m.packages) > 0 {
-		header := resultsHeaderStyle.Render(fmt.Sprintf("📦 Results (%d packages)", len(m.packages)))
+		header := resultsHeaderStyle.Render(fmt.Sprintf("📦 Results (%d packages)", len(m.packages))) +
+			" " + helpDescStyle.Render("sorted by "+m.sortMode.String())
 		content.WriteString(header)
 		content.WriteString("\n\n")
 
@@ -300,14 +487,7 @@ func (m *Model) renderSearch() string {
 
 	if m.message != "" {
 		content.WriteString("\n")
-		switch m.messageType {
-		case "success":
-			content.WriteString(successMessageStyle.Render(m.message))
-		case "error":
-			content.WriteString(errorMessageStyle.Render(m.message))
-		default:
-			content.WriteString(infoMessageStyle.Render(m.message))
-		}
+		content.WriteString(m.renderMessage())
 	}
 
 	content.WriteString("\n")
@@ -341,18 +521,58 @@ func (m *Model) renderPackageItem(idx int) string {
 	item.WriteString(" " + name)
 
 	// Badges
-	if pkg.IsInstalled {
-		item.WriteString(installedBadge.Render("installed"))
+	if pkg.Stdlib {
+		item.WriteString(stdlibBadge.Render("stdlib"))
+	} else if pkg.InModule {
+		badge := "in module"
+		if pkg.RequiredVersion != "" {
+			badge += " " + pkg.RequiredVersion
+		}
+		if pkg.Indirect {
+			badge += " (indirect)"
+		}
+		item.WriteString(installedBadge.Render(badge))
+	} else if pkg.InModCache {
+		item.WriteString(modCacheBadge.Render("mod cache"))
+	}
+	if pkg.Tool {
+		item.WriteString(toolBadge.Render("tool"))
+	} else if pkg.Command && !pkg.InGoBin {
+		item.WriteString(toolBadge.Render("command"))
+	}
+	if pkg.InGoBin {
+		item.WriteString(installedBadge.Render("in GOBIN"))
 	}
 	if pkg.Version != "" {
-		item.WriteString(" " + helpStyle.Render("v"+pkg.Version))
+		item.WriteString(" " + helpStyle.Render(packages.CanonicalVersion(pkg.Version)))
 	}
 	if m.installedPkgs[pkg.ImportPath] {
 		item.WriteString(cachedBadge.Render("cached"))
 	}
+	if pkg.License != "" {
+		if len(m.licenseViolations([]cache.Package{pkg})) > 0 {
+			item.WriteString(deniedLicenseBadge.Render(pkg.License))
+		} else {
+			item.WriteString(licenseBadge.Render(pkg.License))
+		}
+	}
+	if findings := m.vulnsFor(pkg); len(findings) > 0 {
+		item.WriteString(retractedBadge.Render(vulnLabel(findings)))
+	}
+	if pkg.Banned {
+		item.WriteString(retractedBadge.Render("banned"))
+	}
+	if len(pkg.Unverified) > 0 {
+		item.WriteString(cachedBadge.Render(strings.Join(pkg.Unverified, ", ") + " unverified"))
+	}
 
 	item.WriteString("\n")
 
+	if pkg.Banned && pkg.PolicyReason != "" {
+		item.WriteString(policyReasonStyle.Render("⛔ " + TruncateText(pkg.PolicyReason, 70)))
+		item.WriteString("\n")
+	}
+
 	// Description
 	if pkg.Description != "" {
 		desc := TruncateText(pkg.Description, 70)
@@ -371,11 +591,29 @@ func (m *Model) renderOptions() string {
 
 	title := dialogTitleStyle.Render(fmt.Sprintf("📦 %d package(s) selected", len(selected)))
 
-	options := []string{
-		"[G] Give me the command",
-		"[D] Download for me",
-		"[C] Cancel",
+	var options []string
+	if !allStdlib(selected) {
+		options = append(options,
+			"[G] Give me the command",
+			"[D] Download for me (preview go.mod changes first)",
+		)
+	}
+	if len(policy.Banned(selected)) > 0 {
+		options = append(options, "[F] Force download despite the package policy")
+	}
+	if hasCommands(selected) {
+		options = append(options, "[T] Install tool (go install into GOBIN)")
+		if m.pkgManager.Project() != nil && m.pkgManager.SupportsToolDirective() {
+			options = append(options, "[P] Add as project tool (tool directive in go.mod)")
+		}
 	}
+	if project := m.pkgManager.Project(); project != nil && len(project.Modules) > 1 {
+		options = append(options, "[M] Target module: "+m.targetModulesLabel())
+	}
+	if m.importFile != "" {
+		options = append(options, "[I] Import into "+filepath.Base(m.importFile)+" (D imports too)")
+	}
+	options = append(options, "[Y] Copy the import line", "[C] Cancel")
 
 	var optionList strings.Builder
 	for _, opt := range options {
@@ -390,12 +628,330 @@ func (m *Model) renderOptions() string {
 		"",
 		optionList.String(),
 	)
+	if warnings := m.renderPolicyWarnings(selected); warnings != "" {
+		content = lipgloss.JoinVertical(lipgloss.Center, content, warnings)
+	}
+	if warnings := m.renderLicenseWarnings(selected); warnings != "" {
+		content = lipgloss.JoinVertical(lipgloss.Center, content, warnings)
+	}
+	if warnings := m.renderVulnWarnings(selected); warnings != "" {
+		content = lipgloss.JoinVertical(lipgloss.Center, content, warnings)
+	}
 
 	return lipgloss.Place(m.width, m.height,
 		lipgloss.Center, lipgloss.Center,
 		dialogBoxStyle.Render(content))
 }
 
+// lists the selected packages that the package policy bans
+func (m *Model) renderPolicyWarnings(selected []cache.Package) string {
+	var lines []string
+	for _, pkg := range policy.Banned(selected) {
+		line := "⛔ " + pkg.Name + " is banned"
+		if pkg.PolicyReason != "" {
+			line += ": " + pkg.PolicyReason
+		}
+		lines = append(lines, blockedStyle.Render(TruncateText(line, 46)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lists the selected packages whose license the policy does not allow
+func (m *Model) renderLicenseWarnings(selected []cache.Package) string {
+	violations := m.licenseViolations(selected)
+	if len(violations) == 0 {
+		if m.licensePending(selected) {
+			return helpDescStyle.Render("Checking licenses before installing...")
+		}
+		return ""
+	}
+
+	style, icon := warningStyle, "⚠"
+	if m.config.LicensePolicy.Blocks() {
+		style, icon = blockedStyle, "⛔"
+	}
+
+	var lines []string
+	for _, v := range violations {
+		lines = append(lines, style.Render(TruncateText(icon+" "+v, 46)))
+	}
+	if m.config.LicensePolicy.Blocks() {
+		lines = append(lines, style.Render("Installing is blocked by the license policy"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lists the vulnerabilities of the module versions the selection would add
+func (m *Model) renderVulnWarnings(selected []cache.Package) string {
+	var lines []string
+	seen := make(map[module.Version]bool)
+	for _, pkg := range selected {
+		mv := m.pkgManager.InstallModule(pkg)
+		if seen[mv] {
+			continue
+		}
+		seen[mv] = true
+
+		for _, f := range m.vulns[mv] {
+			line := fmt.Sprintf("⚠ %s@%s: %s", mv.Path, mv.Version, f.ID)
+			if f.Fixed != "" {
+				line += " (fixed in " + f.Fixed + ")"
+			}
+			lines = append(lines, warningStyle.Render(TruncateText(line, 46)))
+		}
+	}
+
+	if len(lines) == 0 && m.vulnErr != nil {
+		return helpDescStyle.Render(TruncateText("Vulnerability check unavailable: "+m.vulnErr.Error(), 46))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func vulnLabel(findings []vuln.Finding) string {
+	if len(findings) == 1 {
+		return "1 vuln"
+	}
+	return fmt.Sprintf("%d vulns", len(findings))
+}
+
+func (m *Model) targetModulesLabel() string {
+	modules := m.selectedModules()
+	switch len(modules) {
+	case 0:
+		return m.pkgManager.Project().ModulePath + " (current)"
+	case 1:
+		return modules[0].Path
+	default:
+		return fmt.Sprintf("%d modules", len(modules))
+	}
+}
+
+func (m *Model) renderModules() string {
+	project := m.pkgManager.Project()
+	if project == nil {
+		return ""
+	}
+
+	title := dialogTitleStyle.Render("🗂  Add to workspace modules")
+
+	var list strings.Builder
+	for i, mod := range project.Modules {
+		cursor := "  "
+		name := mod.Path
+		if i == m.moduleCursor {
+			cursor = selectedPackageStyle.Render(">") + " "
+			name = selectedPackageStyle.Render(name)
+		}
+
+		list.WriteString(cursor + RenderCheckbox(m.targetModules[mod.Dir]) + " " + name)
+		if rel, err := filepath.Rel(filepath.Dir(project.GoWorkPath), mod.Dir); err == nil {
+			list.WriteString(" " + helpDescStyle.Render(rel))
+		}
+		list.WriteString("\n")
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		title,
+		"",
+		list.String(),
+		helpStyle.Render("[↑↓] Navigate  [Tab] Toggle  [Enter] Done"),
+	)
+
+	return lipgloss.Place(m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		dialogBoxStyle.Width(70).Render(content))
+}
+
+// renders m.message in the style of its type
+func (m *Model) renderMessage() string {
+	switch m.messageType {
+	case "success":
+		return successMessageStyle.Render(m.message)
+	case "error":
+		return errorMessageStyle.Render(m.message)
+	default:
+		return infoMessageStyle.Render(m.message)
+	}
+}
+
+func (m *Model) renderTools() string {
+	mod := m.pkgManager.Project().Modules[0]
+
+	title := dialogTitleStyle.Render("🔧 Tools of " + mod.Path)
+
+	var list strings.Builder
+	if len(mod.Tools) == 0 {
+		list.WriteString(emptyStateStyle.Render("No tool directives in go.mod"))
+		list.WriteString("\n")
+	}
+	for i, tool := range mod.Tools {
+		cursor := "  "
+		name := tool
+		if i == m.toolCursor {
+			cursor = selectedPackageStyle.Render(">") + " "
+			name = selectedPackageStyle.Render(name)
+		}
+
+		list.WriteString(cursor + name)
+		if req, ok := mod.Lookup(tool); ok && req.Version != "" {
+			list.WriteString(" " + helpDescStyle.Render(req.Version))
+		}
+		if m.pkgManager.IsBinaryInstalled(tool) {
+			list.WriteString(installedBadge.Render("in GOBIN"))
+		}
+		list.WriteString("\n")
+	}
+
+	sections := []string{title, "", list.String()}
+	if m.message != "" {
+		sections = append(sections, m.renderMessage())
+	}
+	sections = append(sections, helpStyle.Render("[↑↓] Navigate  [X] Remove  [Esc] Back  · add tools from search results with [P]"))
+
+	return lipgloss.Place(m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		dialogBoxStyle.Width(80).Render(lipgloss.JoinVertical(lipgloss.Left, sections...)))
+}
+
+func (m *Model) renderDeps() string {
+	mod := m.pkgManager.Project().Modules[0]
+	reqs := mod.Requirements()
+
+	title := dialogTitleStyle.Render("📚 Dependencies of " + mod.Path)
+
+	var list strings.Builder
+	if len(reqs) == 0 {
+		list.WriteString(emptyStateStyle.Render("go.mod has no requirements"))
+		list.WriteString("\n")
+	}
+
+	// keep the cursor in the middle of long lists
+	maxVisible := m.height - 14
+	if maxVisible < 3 {
+		maxVisible = 3
+	}
+	start := 0
+	if len(reqs) > maxVisible {
+		start = m.depCursor - maxVisible/2
+		if start < 0 {
+			start = 0
+		}
+		if start > len(reqs)-maxVisible {
+			start = len(reqs) - maxVisible
+		}
+	}
+
+	for i := start; i < len(reqs) && i < start+maxVisible; i++ {
+		req := reqs[i]
+		line := fmt.Sprintf("%-50s %s", TruncateText(req.Path, 50), req.Version)
+		if i == m.depCursor {
+			line = selectedPackageStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		if req.Indirect {
+			line += " " + helpDescStyle.Render("// indirect")
+		}
+		list.WriteString(line + "\n")
+	}
+
+	sections := []string{title, "", list.String()}
+	if m.message != "" {
+		sections = append(sections, m.renderMessage())
+	}
+	sections = append(sections, helpStyle.Render("[↑↓] Navigate  [U] Upgrade to latest  [D] Downgrade…  [X] Remove  [Esc] Back"))
+
+	return lipgloss.Place(m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		dialogBoxStyle.Width(90).Align(lipgloss.Left).Render(lipgloss.JoinVertical(lipgloss.Left, sections...)))
+}
+
+func (m *Model) renderOutdated() string {
+	title := dialogTitleStyle.Render("⬆  Outdated dependencies of " + m.pkgManager.Project().ModulePath)
+
+	var list strings.Builder
+	switch {
+	case m.loadingUpdates:
+		list.WriteString(m.spinner.View() + " Checking GOPROXY for newer versions...")
+	case m.updatesErr != nil:
+		list.WriteString(errorMessageStyle.Render("Failed to check for updates: " + m.updatesErr.Error()))
+	case len(m.updates) == 0:
+		list.WriteString(emptyStateStyle.Render("All requirements are up to date"))
+	default:
+		for _, idx := range m.getVisibleUpdates() {
+			u := m.updates[idx]
+
+			target := u.NewVersion
+			if u.NewPath != u.Path {
+				target = u.NewPath + " " + u.NewVersion
+			}
+			line := fmt.Sprintf("%s %s → %s", TruncateText(u.Path, 45), u.Version, target)
+			if idx == m.cursor {
+				line = selectedPackageStyle.Render(line)
+			}
+
+			list.WriteString(RenderCheckbox(m.selected[idx]) + " " + line + updateBadge(u.Kind))
+			if findings := m.vulnsFor(m.packages[idx]); len(findings) > 0 {
+				list.WriteString(retractedBadge.Render(vulnLabel(findings)))
+			}
+			if u.Indirect {
+				list.WriteString(" " + helpDescStyle.Render("// indirect"))
+			}
+			list.WriteString("\n")
+		}
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		title,
+		"",
+		list.String(),
+		helpStyle.Render(fmt.Sprintf("%d selected  [Tab] Select  [Shift+A] All  [Shift+N] None  [Enter] Upgrade  [G] Give me the command  [Esc] Back",
+			len(m.getSelectedPackages()))),
+	)
+
+	return lipgloss.Place(m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		dialogBoxStyle.Width(100).Align(lipgloss.Left).Render(content))
+}
+
+func updateBadge(kind packages.UpdateKind) string {
+	switch kind {
+	case packages.UpdatePatch:
+		return installedBadge.Render(string(kind))
+	case packages.UpdateMinor:
+		return modCacheBadge.Render(string(kind))
+	default:
+		return majorBadge.Render(string(kind))
+	}
+}
+
+func (m *Model) getVisibleUpdates() []int {
+	maxVisible := m.height - 12
+	if maxVisible < 1 {
+		maxVisible = 1
+	}
+
+	start := 0
+	end := len(m.updates)
+	if end > maxVisible {
+		start = m.cursor - maxVisible/2
+		if start < 0 {
+			start = 0
+		}
+		end = start + maxVisible
+		if end > len(m.updates) {
+			end = len(m.updates)
+			start = end - maxVisible
+		}
+	}
+
+	result := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		result = append(result, i)
+	}
+	return result
+}
+
 func (m *Model) renderCommands() string {
 	title := dialogTitleStyle.Render("📋 Installation Commands")
 
@@ -423,6 +979,368 @@ func (m *Model) renderCommands() string {
 		dialogBoxStyle.Width(70).Render(content))
 }
 
+func (m *Model) renderVersions() string {
+	var current string
+	enterHelp := "[Enter] Pin version"
+	if m.versionDep != nil {
+		current = m.versionDep.Version
+		enterHelp = "[Enter] Switch go.mod to version"
+	} else {
+		if m.versionPkgIdx >= len(m.packages) {
+			return ""
+		}
+		current = m.packages[m.versionPkgIdx].RequiredVersion
+	}
+	modulePath := m.versionModulePath()
+
+	title := dialogTitleStyle.Render("🏷  Versions of " + modulePath)
+
+	var list strings.Builder
+	switch {
+	case m.loadingVersions:
+		list.WriteString(m.spinner.View() + " Fetching versions...")
+	case len(m.versions) == 0:
+		list.WriteString(emptyStateStyle.Render("No versions found"))
+	default:
+		for _, idx := range m.getVisibleVersions() {
+			list.WriteString(m.renderVersionItem(idx, current))
+			list.WriteString("\n")
+		}
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		title,
+		"",
+		list.String(),
+		helpStyle.Render("[↑↓] Navigate  "+enterHelp+"  [Esc] Back"),
+	)
+
+	return lipgloss.Place(m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		dialogBoxStyle.Width(70).Align(lipgloss.Left).Render(content))
+}
+
+func (m *Model) renderVersionItem(idx int, current string) string {
+	v := m.versions[idx]
+
+	released := "          "
+	if !v.Time.IsZero() {
+		released = v.Time.Format("2006-01-02")
+	}
+
+	line := fmt.Sprintf("%-40s %s", v.Version, helpDescStyle.Render(released))
+	if idx == m.versionCursor {
+		line = selectedPackageStyle.Render("> "+fmt.Sprintf("%-40s", v.Version)) + " " + helpDescStyle.Render(released)
+	} else {
+		line = "  " + line
+	}
+
+	if v.Version == current {
+		line += installedBadge.Render("current")
+	}
+	if v.Pseudo {
+		line += modCacheBadge.Render("pseudo")
+	}
+	if v.Retracted {
+		line += retractedBadge.Render("retracted")
+		if v.Rationale != "" && idx == m.versionCursor {
+			line += "\n    " + helpDescStyle.Render(TruncateText(v.Rationale, 60))
+		}
+	}
+
+	return line
+}
+
+func (m *Model) getVisibleVersions() []int {
+	maxVisible := m.height - 12
+	if maxVisible < 1 {
+		maxVisible = 1
+	}
+
+	start := 0
+	end := len(m.versions)
+	if end > maxVisible {
+		start = m.versionCursor - maxVisible/2
+		if start < 0 {
+			start = 0
+		}
+		end = start + maxVisible
+		if end > len(m.versions) {
+			end = len(m.versions)
+			start = end - maxVisible
+		}
+	}
+
+	result := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		result = append(result, i)
+	}
+	return result
+}
+
+func (m *Model) renderDetails() string {
+	if m.detailsPkgIdx >= len(m.packages) {
+		return ""
+	}
+	pkg := m.packages[m.detailsPkgIdx]
+	width := m.width - 8
+
+	var content strings.Builder
+
+	title := "📄 " + pkg.Name
+	if m.selected[m.detailsPkgIdx] {
+		title += " ✓"
+	}
+	content.WriteString(titleStyle.Render(title))
+	content.WriteString("\n")
+	content.WriteString(packagePathStyle.Render(pkg.ImportPath))
+	content.WriteString("\n\n")
+
+	details := m.details[pkg.ImportPath]
+	switch {
+	case m.loadingDetails:
+		content.WriteString(m.spinner.View() + " Fetching package details...")
+		content.WriteString("\n")
+	case details == nil:
+		msg := "No details available"
+		if m.detailsErr != nil {
+			msg = "Failed to fetch details: " + m.detailsErr.Error()
+		}
+		content.WriteString(errorMessageStyle.Render(msg))
+		content.WriteString("\n")
+	default:
+		if details.Description != "" {
+			content.WriteString(packageDescStyle.Width(width).Render(details.Description))
+			content.WriteString("\n\n")
+		}
+
+		version := details.Version
+		if version == "" {
+			version = pkg.Version
+		}
+		fields := []struct{ key, value string }{
+			{"Version", packages.CanonicalVersion(version)},
+			{"Published", details.Published},
+			{"License", details.License},
+			{"Imported by", formatCount(details.ImportedBy)},
+			{"Repository", details.Repository},
+		}
+		if pkg.InModule {
+			fields = append(fields, struct{ key, value string }{"Required", pkg.RequiredVersion})
+		}
+		for _, f := range fields {
+			if f.value == "" {
+				continue
+			}
+			content.WriteString(m.renderDetailField(f.key, f.value))
+			content.WriteString("\n")
+		}
+
+		if readme := m.readmeLines(details.Readme, width); len(readme) > 0 {
+			content.WriteString("\n")
+			content.WriteString(resultsHeaderStyle.Render("README"))
+			content.WriteString("\n\n")
+
+			// the header above takes roughly 15 lines
+			maxVisible := m.height - 15
+			if maxVisible < 3 {
+				maxVisible = 3
+			}
+			start := m.detailsScroll
+			if start > len(readme)-1 {
+				start = len(readme) - 1
+			}
+			end := start + maxVisible
+			if end > len(readme) {
+				end = len(readme)
+			}
+			content.WriteString(strings.Join(readme[start:end], "\n"))
+			content.WriteString("\n")
+		}
+	}
+
+	content.WriteString("\n")
+	content.WriteString(footerStyle.Render(
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			helpKeyStyle.Render("[↑↓]")+" Scroll  ",
+			helpKeyStyle.Render("[Tab]")+" Select  ",
+			helpKeyStyle.Render("[Shift+V]")+" Versions  ",
+			helpKeyStyle.Render("[Esc]")+" Back",
+		),
+	))
+
+	return appStyle.Width(m.width - 4).Render(content.String())
+}
+
+func (m *Model) renderDetailField(key, value string) string {
+	return fmt.Sprintf("%s %s",
+		helpKeyStyle.Width(12).Render(key),
+		helpDescStyle.Render(value))
+}
+
+// wraps the README text to width, styling markdown headings
+func (m *Model) readmeLines(readme string, width int) []string {
+	if readme == "" {
+		return nil
+	}
+
+	var lines []string
+	for _, line := range strings.Split(readme, "\n") {
+		if heading, ok := strings.CutPrefix(line, "# "); ok {
+			lines = append(lines, readmeHeadingStyle.Render(heading))
+			continue
+		}
+		if strings.HasPrefix(line, "    ") {
+			// keep code blocks unwrapped
+			lines = append(lines, readmeCodeStyle.Render(TruncateText(line, width)))
+			continue
+		}
+		wrapped := lipgloss.NewStyle().Width(width).Render(line)
+		lines = append(lines, strings.Split(wrapped, "\n")...)
+	}
+	return lines
+}
+
+func formatCount(n int) string {
+	if n == 0 {
+		return ""
+	}
+
+	s := fmt.Sprintf("%d", n)
+	var out strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			out.WriteByte(',')
+		}
+		out.WriteRune(r)
+	}
+	return out.String()
+}
+
+func (m *Model) renderPreview() string {
+	title := dialogTitleStyle.Render(fmt.Sprintf("🔍 Changes for %d package(s)", len(m.previewPkgs)))
+
+	var body string
+	help := "[Enter] Install  [↑↓] Scroll  [Esc] Cancel"
+
+	if m.loadingPreview {
+		body = m.spinner.View() + " Resolving with go get in a scratch go.mod..."
+		help = "[Esc] Cancel"
+	} else {
+		lines := m.previewLines()
+
+		maxVisible := m.height - 10
+		if maxVisible < 3 {
+			maxVisible = 3
+		}
+		start := m.previewScroll
+		if start > len(lines)-1 {
+			start = len(lines) - 1
+		}
+		end := start + maxVisible
+		if end > len(lines) {
+			end = len(lines)
+		}
+		body = strings.Join(lines[start:end], "\n")
+
+		for _, p := range m.previews {
+			if p.err != nil {
+				help = "[Enter] Install anyway  [↑↓] Scroll  [Esc] Cancel"
+			}
+		}
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		title,
+		"",
+		body,
+		"",
+		helpStyle.Render(help),
+	)
+
+	return lipgloss.Place(m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		dialogBoxStyle.Width(80).Render(content))
+}
+
+// renders the previews of all target modules as one scrollable list
+func (m *Model) previewLines() []string {
+	var lines []string
+
+	for i, p := range m.previews {
+		if len(m.previews) > 1 {
+			if i > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, resultsHeaderStyle.UnsetMargins().Render("▸ "+p.module.Path))
+		}
+
+		switch {
+		case p.err != nil:
+			lines = append(lines, errorMessageStyle.Render("Preview failed"))
+			for _, line := range strings.Split(strings.TrimSpace(p.err.Error()), "\n") {
+				lines = append(lines, helpDescStyle.Render(TruncateText(line, 74)))
+			}
+			continue
+		case p.preview == nil || len(p.preview.Changes) == 0:
+			lines = append(lines, infoMessageStyle.Render("go.mod would not change"))
+			continue
+		}
+
+		for _, c := range p.preview.Changes {
+			lines = append(lines, renderChange(c))
+		}
+		if p.preview.SumAdded > 0 {
+			lines = append(lines, helpDescStyle.Render(fmt.Sprintf("go.sum: %d new line(s)", p.preview.SumAdded)))
+		}
+		if p.preview.HasTransitiveChanges() {
+			lines = append(lines, "", warningStyle.Render("⚠ Existing requirements would change"))
+		}
+
+		lines = append(lines, "")
+		for _, line := range strings.Split(strings.TrimRight(p.preview.Diff, "\n"), "\n") {
+			lines = append(lines, renderDiffLine(TruncateText(line, 74)))
+		}
+	}
+
+	return lines
+}
+
+func renderChange(c packages.Change) string {
+	var line string
+	switch c.Kind {
+	case packages.ChangeAdded:
+		line = diffAddStyle.Render(fmt.Sprintf("+ %s %s", c.Path, c.NewVersion))
+	case packages.ChangeRemoved:
+		line = diffRemoveStyle.Render(fmt.Sprintf("- %s %s", c.Path, c.OldVersion))
+	default:
+		text := fmt.Sprintf("~ %s %s → %s", c.Path, c.OldVersion, c.NewVersion)
+		if c.Requested {
+			line = diffAddStyle.Render(text)
+		} else {
+			line = warningStyle.Render(text)
+		}
+	}
+
+	if c.Indirect {
+		line += helpDescStyle.Render(" // indirect")
+	}
+	return line
+}
+
+func renderDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"), strings.HasPrefix(line, "@@"):
+		return helpDescStyle.Render(line)
+	case strings.HasPrefix(line, "+"):
+		return diffAddStyle.Render(line)
+	case strings.HasPrefix(line, "-"):
+		return diffRemoveStyle.Render(line)
+	default:
+		return line
+	}
+}
+
 func (m *Model) renderInstalling() string {
 	title := titleStyle.Render("📦 Installing Packages")
 
@@ -432,18 +1350,57 @@ func (m *Model) renderInstalling() string {
 	if message == "" {
 		message = "Preparing installation..."
 	}
+	status := m.spinner.View() + " " + TruncateText(message, 60)
+	if !m.installing {
+		status = errorMessageStyle.Render(message)
+	}
 
-	content := lipgloss.JoinVertical(lipgloss.Center,
-		title,
-		"",
-		m.spinner.View()+" "+message,
-		"",
-		progressBar,
-	)
+	sections := []string{title, "", status, "", progressBar}
+
+	// the tail of the go get output
+	logLines := m.height - 14 - 2*len(m.installFailures)
+	if logLines < 3 {
+		logLines = 3
+	}
+	if len(m.installLog) > 0 {
+		start := len(m.installLog) - logLines
+		if start < 0 {
+			start = 0
+		}
+		var log strings.Builder
+		for _, line := range m.installLog[start:] {
+			log.WriteString(helpDescStyle.Render(TruncateText(line, 64)))
+			log.WriteString("\n")
+		}
+		sections = append(sections, "", strings.TrimRight(log.String(), "\n"))
+	}
+
+	if len(m.installFailures) > 0 {
+		sections = append(sections, "")
+		for _, f := range m.installFailures {
+			sections = append(sections,
+				errorMessageStyle.Render("✗ "+f.pkg.ImportPath),
+				"  "+helpDescStyle.Render(TruncateText(failureReason(f.err), 62)))
+		}
+	}
+
+	if m.installing {
+		sections = append(sections, "", helpStyle.Render("Press [Ctrl+C] to cancel"))
+	} else {
+		sections = append(sections, "", helpStyle.Render("Press [ESC] to go back"))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, sections...)
 
 	return lipgloss.Place(m.width, m.height,
 		lipgloss.Center, lipgloss.Center,
-		dialogBoxStyle.Render(content))
+		dialogBoxStyle.Width(70).Render(content))
+}
+
+// go get reports the cause on the last line of its output
+func failureReason(err error) string {
+	lines := strings.Split(strings.TrimSpace(err.Error()), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
 }
 
 func (m *Model) renderHelp() string {
@@ -457,12 +1414,18 @@ func (m *Model) renderHelp() string {
 		m.renderHelpItem("Enter", "Proceed with selected"),
 		m.renderHelpItem("Esc", "Clear search / Quit if empty"),
 		"",
-		lipgloss.NewStyle().Foreground(accentColor).Bold(true).Render("Commands (Shift+Key or Ctrl+Key):"),
-		m.renderHelpItem("Shift+A", "Select all"),
-		m.renderHelpItem("Shift+N", "Deselect all"),
-		m.renderHelpItem("Shift+H", "Toggle help"),
-		m.renderHelpItem("Shift+C", "Clear cache"),
-		m.renderHelpItem("Shift+Q", "Quit"),
+		lipgloss.NewStyle().Foreground(accentColor).Bold(true).Render("Commands (Alt+Key, Ctrl+Key or F-key):"),
+		m.renderHelpItem("Ctrl+A", "Select all (also Alt+A)"),
+		m.renderHelpItem("Ctrl+N", "Deselect all (also Alt+N)"),
+		m.renderHelpItem("Alt+V, F2", "Pick a version"),
+		m.renderHelpItem("Alt+I, F3", "Package details"),
+		m.renderHelpItem("Alt+T, F4", "Project tools"),
+		m.renderHelpItem("Alt+M, F5", "Manage dependencies"),
+		m.renderHelpItem("Alt+O, F6", "Outdated dependencies"),
+		m.renderHelpItem("Alt+S, F7", "Cycle sort order"),
+		m.renderHelpItem("Alt+C, F8", "Clear cache"),
+		m.renderHelpItem("Ctrl+H, F1", "Toggle help (also Alt+H)"),
+		m.renderHelpItem("Ctrl+Q", "Quit (also Alt+Q)"),
 		"",
 		helpStyle.Render("Press any key to close help..."),
 	)
@@ -486,9 +1449,10 @@ func (m *Model) renderFooter() string {
 		lipgloss.JoinHorizontal(lipgloss.Left,
 			helpKeyStyle.Render("[↑↓]")+" Navigate  ",
 			helpKeyStyle.Render("[Tab]")+" Select  ",
+			helpKeyStyle.Render("[F3]")+" Details  ",
 			helpKeyStyle.Render("[Enter]")+" Proceed  ",
-			helpKeyStyle.Render("[Shift+H]")+" Help  ",
-			helpKeyStyle.Render("[Shift+Q]")+" Quit",
+			helpKeyStyle.Render("[F1]")+" Help  ",
+			helpKeyStyle.Render("[Ctrl+Q]")+" Quit",
 		),
 	))
 
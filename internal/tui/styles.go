@@ -77,6 +77,72 @@ var (
 			MarginLeft(1).
 			Bold(true)
 
+	modCacheBadge = lipgloss.NewStyle().
+			Background(highlightColor).
+			Foreground(bgColor).
+			Padding(0, 1).
+			MarginLeft(1)
+
+	stdlibBadge = lipgloss.NewStyle().
+			Background(accentColor).
+			Foreground(bgColor).
+			Padding(0, 1).
+			MarginLeft(1)
+
+	toolBadge = lipgloss.NewStyle().
+			Background(secondaryColor).
+			Foreground(bgColor).
+			Padding(0, 1).
+			MarginLeft(1)
+
+	licenseBadge = lipgloss.NewStyle().
+			Background(borderColor).
+			Foreground(fgColor).
+			Padding(0, 1).
+			MarginLeft(1)
+
+	deniedLicenseBadge = lipgloss.NewStyle().
+				Background(errorColor).
+				Foreground(bgColor).
+				Padding(0, 1).
+				MarginLeft(1).
+				Strikethrough(true)
+
+	majorBadge = lipgloss.NewStyle().
+			Background(warningColor).
+			Foreground(bgColor).
+			Padding(0, 1).
+			MarginLeft(1)
+
+	retractedBadge = lipgloss.NewStyle().
+			Background(errorColor).
+			Foreground(bgColor).
+			Padding(0, 1).
+			MarginLeft(1)
+
+	readmeHeadingStyle = lipgloss.NewStyle().
+				Foreground(accentColor).
+				Bold(true)
+
+	readmeCodeStyle = lipgloss.NewStyle().
+			Foreground(dimmedColor)
+
+	diffAddStyle = lipgloss.NewStyle().
+			Foreground(accentColor)
+
+	diffRemoveStyle = lipgloss.NewStyle().
+			Foreground(errorColor)
+
+	warningStyle = lipgloss.NewStyle().
+			Foreground(warningColor)
+
+	blockedStyle = lipgloss.NewStyle().
+			Foreground(errorColor)
+
+	policyReasonStyle = lipgloss.NewStyle().
+				Foreground(errorColor).
+				MarginLeft(2)
+
 	cachedBadge = lipgloss.NewStyle().
 			Background(warningColor).
 			Foreground(bgColor).
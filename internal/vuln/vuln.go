@@ -0,0 +1,376 @@
+package vuln
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+const DefaultURL = "https://vuln.go.dev"
+
+var (
+	// ErrDisabled is returned when the database source is "off"
+	ErrDisabled = errors.New("vulnerability checks are disabled")
+	// ErrNotFound is returned for entries missing from the database
+	ErrNotFound = errors.New("not found")
+)
+
+// Entry is a record of the Go vulnerability database in OSV format
+type Entry struct {
+	ID        string     `json:"id"`
+	Modified  time.Time  `json:"modified"`
+	Withdrawn *time.Time `json:"withdrawn,omitempty"`
+	Aliases   []string   `json:"aliases,omitempty"`
+	Summary   string     `json:"summary,omitempty"`
+	Details   string     `json:"details,omitempty"`
+	Affected  []Affected `json:"affected"`
+}
+
+type Affected struct {
+	Package Package `json:"package"`
+	Ranges  []Range `json:"ranges,omitempty"`
+}
+
+type Package struct {
+	Name      string `json:"name"`
+	Ecosystem string `json:"ecosystem"`
+}
+
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+// Event versions carry no "v" prefix; an introduced version of "0" means
+// every version since the first
+type Event struct {
+	Introduced string `json:"introduced,omitempty"`
+	Fixed      string `json:"fixed,omitempty"`
+}
+
+// Finding is a vulnerability that affects a module version
+type Finding struct {
+	ID      string   `json:"id"`
+	Aliases []string `json:"aliases,omitempty"`
+	Summary string   `json:"summary,omitempty"`
+	// the first version with a fix; empty when none was released
+	Fixed string `json:"fixed,omitempty"`
+}
+
+// the entries of index/modules.json that we use
+type indexModule struct {
+	Path  string `json:"path"`
+	Vulns []struct {
+		ID string `json:"id"`
+	} `json:"vulns"`
+}
+
+// Client reads a vulnerability database laid out like vuln.go.dev, served
+// over HTTP or mirrored to a local directory. A directory without an index
+// may also just hold OSV files, such as an extracted osv.dev export
+type Client struct {
+	url      string
+	dir      string
+	disabled bool
+	client   *http.Client
+
+	mu      sync.Mutex
+	loaded  bool
+	loadErr error
+	// module path -> IDs of the entries that mention it
+	index   map[string][]string
+	entries map[string]*Entry
+}
+
+// returns GOVULNDB when set, as govulncheck does, and configured otherwise
+func Source(configured string) string {
+	if env := os.Getenv("GOVULNDB"); env != "" {
+		return env
+	}
+	return configured
+}
+
+// creates a client for source, which is a URL, a file:// URL or a directory.
+// An empty source means DefaultURL and "off" disables checks
+func New(source string) *Client {
+	c := &Client{
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		entries: make(map[string]*Entry),
+	}
+
+	switch {
+	case source == "":
+		c.url = DefaultURL
+	case source == "off":
+		c.disabled = true
+	case strings.HasPrefix(source, "file://"):
+		if u, err := url.Parse(source); err == nil {
+			c.dir = filepath.FromSlash(u.Path)
+		} else {
+			c.dir = strings.TrimPrefix(source, "file://")
+		}
+	case strings.HasPrefix(source, "http://"), strings.HasPrefix(source, "https://"):
+		c.url = strings.TrimSuffix(source, "/")
+	default:
+		c.dir = source
+	}
+
+	return c
+}
+
+// reports whether the client was created with the "off" source
+func (c *Client) Disabled() bool {
+	return c.disabled
+}
+
+// returns the vulnerabilities affecting modulePath at version, ordered by ID
+func (c *Client) Check(modulePath, version string) ([]Finding, error) {
+	if c.disabled {
+		return nil, ErrDisabled
+	}
+	if !semver.IsValid(version) {
+		return nil, fmt.Errorf("invalid version %q", version)
+	}
+
+	ids, err := c.lookup(modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, id := range ids {
+		entry, err := c.Entry(id)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Withdrawn != nil {
+			continue
+		}
+
+		for _, a := range entry.Affected {
+			if a.Package.Name != modulePath || (a.Package.Ecosystem != "" && a.Package.Ecosystem != "Go") {
+				continue
+			}
+			if affected, fixed := affects(a.Ranges, version); affected {
+				findings = append(findings, Finding{
+					ID:      entry.ID,
+					Aliases: entry.Aliases,
+					Summary: entry.Summary,
+					Fixed:   fixed,
+				})
+				break
+			}
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		return findings[i].ID < findings[j].ID
+	})
+
+	return findings, nil
+}
+
+// returns the entry with the given ID
+func (c *Client) Entry(id string) (*Entry, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[id]
+	c.mu.Unlock()
+	if ok {
+		return entry, nil
+	}
+
+	data, err := c.read("ID/" + id + ".json")
+	if err != nil {
+		return nil, err
+	}
+
+	entry = &Entry{}
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", id, err)
+	}
+
+	c.mu.Lock()
+	c.entries[id] = entry
+	c.mu.Unlock()
+
+	return entry, nil
+}
+
+func (c *Client) lookup(modulePath string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.loaded {
+		c.index, c.loadErr = c.loadIndex()
+		c.loaded = true
+	}
+	if c.loadErr != nil {
+		return nil, c.loadErr
+	}
+
+	return c.index[modulePath], nil
+}
+
+// reads index/modules.json, or for a directory without one, every OSV file
+// in it. Called with c.mu held
+func (c *Client) loadIndex() (map[string][]string, error) {
+	data, err := c.read("index/modules.json")
+	if errors.Is(err, ErrNotFound) && c.dir != "" {
+		return c.scanDir()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var modules []indexModule
+	if err := json.Unmarshal(data, &modules); err != nil {
+		return nil, fmt.Errorf("failed to parse vulnerability index: %w", err)
+	}
+
+	index := make(map[string][]string)
+	for _, mod := range modules {
+		for _, v := range mod.Vulns {
+			index[mod.Path] = append(index[mod.Path], v.ID)
+		}
+	}
+	return index, nil
+}
+
+func (c *Client) scanDir() (map[string][]string, error) {
+	index := make(map[string][]string)
+
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var entry Entry
+		// other JSON files are not entries; skip them
+		if json.Unmarshal(data, &entry) != nil || entry.ID == "" {
+			return nil
+		}
+
+		c.entries[entry.ID] = &entry
+		seen := make(map[string]bool)
+		for _, a := range entry.Affected {
+			if name := a.Package.Name; !seen[name] {
+				seen[name] = true
+				index[name] = append(index[name], entry.ID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vulnerability database: %w", err)
+	}
+
+	return index, nil
+}
+
+// reads a file of the database, relative to its root
+func (c *Client) read(name string) ([]byte, error) {
+	if c.dir != "" {
+		data, err := os.ReadFile(filepath.Join(c.dir, filepath.FromSlash(name)))
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s: %w", name, ErrNotFound)
+		}
+		return data, err
+	}
+
+	u := c.url + "/" + name
+	resp, err := c.client.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s: %w", u, ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from %s: %d", u, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", u, err)
+	}
+	return data, nil
+}
+
+// reports whether version falls into one of the SEMVER ranges, and if so
+// the version that fixes it
+func affects(ranges []Range, version string) (bool, string) {
+	// an entry without ranges affects every version
+	if len(ranges) == 0 {
+		return true, ""
+	}
+
+	for _, r := range ranges {
+		if r.Type != "SEMVER" {
+			continue
+		}
+
+		events := make([]Event, len(r.Events))
+		copy(events, r.Events)
+		sort.SliceStable(events, func(i, j int) bool {
+			return semver.Compare(eventVersion(events[i]), eventVersion(events[j])) < 0
+		})
+
+		affected := false
+		fixed := ""
+		for _, e := range events {
+			v := eventVersion(e)
+			if semver.Compare(version, v) < 0 {
+				if affected && e.Fixed != "" {
+					fixed = v
+					break
+				}
+				continue
+			}
+			affected = e.Introduced != ""
+		}
+
+		if affected {
+			return true, fixed
+		}
+	}
+
+	return false, ""
+}
+
+func eventVersion(e Event) string {
+	v := e.Introduced
+	if v == "" {
+		v = e.Fixed
+	}
+	if v == "0" {
+		return "v0.0.0-0"
+	}
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	return v
+}
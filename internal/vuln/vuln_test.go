@@ -0,0 +1,131 @@
+package vuln
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testEntry = `{
+  "id": "GO-2024-0001",
+  "modified": "2024-01-01T00:00:00Z",
+  "aliases": ["CVE-2024-0001"],
+  "summary": "Panic on malformed input in example.com/a",
+  "affected": [{
+    "package": {"name": "example.com/a", "ecosystem": "Go"},
+    "ranges": [{
+      "type": "SEMVER",
+      "events": [{"introduced": "0"}, {"fixed": "1.2.1"}, {"introduced": "1.4.0"}, {"fixed": "1.4.2"}]
+    }]
+  }]
+}`
+
+const testIndex = `[{"path": "example.com/a", "vulns": [{"id": "GO-2024-0001", "modified": "2024-01-01T00:00:00Z"}]}]`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "index", "modules.json"), testIndex)
+	writeFile(t, filepath.Join(dir, "ID", "GO-2024-0001.json"), testEntry)
+
+	tests := []struct {
+		version string
+		fixed   string
+		want    bool
+	}{
+		{"v1.0.0", "v1.2.1", true},
+		{"v1.2.1", "", false},
+		{"v1.3.0", "", false},
+		{"v1.4.0", "v1.4.2", true},
+		{"v1.4.2", "", false},
+		{"v0.0.0-20200101000000-abcdefabcdef", "v1.2.1", true},
+	}
+
+	c := New(dir)
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			findings, err := c.Check("example.com/a", tt.version)
+			require.NoError(t, err)
+			if !tt.want {
+				assert.Empty(t, findings)
+				return
+			}
+			require.Len(t, findings, 1)
+			assert.Equal(t, "GO-2024-0001", findings[0].ID)
+			assert.Equal(t, []string{"CVE-2024-0001"}, findings[0].Aliases)
+			assert.Equal(t, tt.fixed, findings[0].Fixed)
+		})
+	}
+
+	findings, err := c.Check("example.com/b", "v1.0.0")
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func TestCheckOSVDirectory(t *testing.T) {
+	// an extracted osv.dev export has no index
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "GO-2024-0001.json"), testEntry)
+	writeFile(t, filepath.Join(dir, "notes.json"), `{"hello": "world"}`)
+
+	findings, err := New("file://"+filepath.ToSlash(dir)).Check("example.com/a", "v1.1.0")
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "Panic on malformed input in example.com/a", findings[0].Summary)
+}
+
+func TestCheckURL(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/index/modules.json":
+			w.Write([]byte(testIndex))
+		case "/ID/GO-2024-0001.json":
+			w.Write([]byte(testEntry))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := New(server.URL)
+	for i := 0; i < 2; i++ {
+		findings, err := c.Check("example.com/a", "v1.4.1")
+		require.NoError(t, err)
+		require.Len(t, findings, 1)
+		assert.Equal(t, "v1.4.2", findings[0].Fixed)
+	}
+	assert.Equal(t, 2, requests, "index and entry are fetched once")
+}
+
+func TestCheckErrors(t *testing.T) {
+	_, err := New("off").Check("example.com/a", "v1.0.0")
+	assert.ErrorIs(t, err, ErrDisabled)
+
+	_, err = New(t.TempDir()).Check("example.com/a", "latest")
+	assert.Error(t, err)
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	_, err = New(server.URL).Check("example.com/a", "v1.0.0")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSource(t *testing.T) {
+	t.Setenv("GOVULNDB", "")
+	assert.Equal(t, "/srv/vulndb", Source("/srv/vulndb"))
+
+	t.Setenv("GOVULNDB", "file:///mirror")
+	assert.Equal(t, "file:///mirror", Source("/srv/vulndb"))
+}
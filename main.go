@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -8,11 +9,16 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/MdSadiqMd/gopick/internal/cache"
+	"github.com/MdSadiqMd/gopick/internal/cli"
 	"github.com/MdSadiqMd/gopick/internal/config"
 	"github.com/MdSadiqMd/gopick/internal/history"
 	"github.com/MdSadiqMd/gopick/internal/packages"
+	"github.com/MdSadiqMd/gopick/internal/policy"
+	"github.com/MdSadiqMd/gopick/internal/proxy"
+	"github.com/MdSadiqMd/gopick/internal/search"
 	"github.com/MdSadiqMd/gopick/internal/term"
 	"github.com/MdSadiqMd/gopick/internal/tui"
+	"github.com/MdSadiqMd/gopick/internal/vuln"
 )
 
 func main() {
@@ -35,10 +41,45 @@ func main() {
 	}
 
 	pm := packages.New(cfg.GoModCachePath)
+	pc := proxy.NewFromEnv()
+	pm.SetResolver(packages.NewResolver(pc))
+	if cfg.InstallStatus != config.InstallStatusModCache {
+		if cwd, err := os.Getwd(); err == nil {
+			// outside a module we fall back to probing the module cache
+			if project, err := packages.LoadProject(cwd); err == nil {
+				pm.SetProject(project)
+			}
+		}
+	}
+
+	s, err := search.FromConfig(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing search providers: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(os.Args) > 1 {
+		app := cli.New(cfg, c, h, pm, s, pc, os.Stdout, os.Stderr)
+		if err := app.Run(os.Args[1:]); err != nil {
+			if errors.Is(err, cli.ErrUsage) {
+				os.Exit(2)
+			}
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	go c.CleanExpired()
 
-	model := tui.New(cfg, c, h, pm)
+	var pol *policy.Policy
+	if cfg.Policy != "" {
+		if pol, err = policy.Load(cfg.Policy, cfg.CacheDir); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: package policy not applied: %v\n", err)
+		}
+	}
+
+	model := tui.New(cfg, c, h, pm, s, pc, vuln.New(vuln.Source(cfg.VulnDB)), pol)
 
 	p := tea.NewProgram(model, tea.WithAltScreen())
 
@@ -53,10 +94,16 @@ func main() {
 			commands := m.GetCommandsToPrint()
 			fullCmd := strings.Join(commands, " && ")
 
-			// Inject the command into the terminal input buffer
-			if err := term.InjectCommandToTTY(fullCmd, m.ShouldAutoRun()); err != nil {
-				// Fallback if injection fails
-				fmt.Fprintln(os.Stderr, "warning: couldn't inject command into terminal; printing instead:")
+			// A shell wrapper places the command on the prompt itself; otherwise
+			// inject it into the terminal input buffer
+			err := term.WriteCommandToShell(fullCmd, m.ShouldAutoRun())
+			if errors.Is(err, term.ErrNoShellIntegration) {
+				err = term.InjectCommandToTTY(fullCmd, m.ShouldAutoRun())
+			}
+			if err != nil {
+				// TIOCSTI is disabled on many newer kernels (dev.tty.legacy_tiocsti=0)
+				fmt.Fprintf(os.Stderr, "warning: couldn't place command on the prompt (%v); printing instead.\n", err)
+				fmt.Fprintln(os.Stderr, `To avoid this, add eval "$(gopick shell-init bash)" (or zsh, fish) to your shell config.`)
 				fmt.Println(fullCmd)
 			}
 		}